@@ -59,6 +59,10 @@ var (
 		Code: http.StatusNotFound,
 		Err:  errors.ErrClusterNotFound,
 	}
+	ErrClusterExists = Error{
+		Code: http.StatusConflict,
+		Err:  errors.ErrClusterExists,
+	}
 	ErrGroupBindingNotFound = Error{
 		Code: http.StatusNotFound,
 		Err:  errors.PolicyNotExistError,
@@ -107,4 +111,24 @@ var (
 		Code: http.StatusConflict,
 		Err:  errors.ErrAuditExists,
 	}
+	ErrHostNotFound = Error{
+		Code: http.StatusNotFound,
+		Err:  errors.ErrHostNotFound,
+	}
+	ErrHostExists = Error{
+		Code: http.StatusConflict,
+		Err:  errors.ErrHostExists,
+	}
+	ErrHostSessionNotFound = Error{
+		Code: http.StatusNotFound,
+		Err:  errors.ErrHostSessionNotFound,
+	}
+	ErrPlanNotFound = Error{
+		Code: http.StatusNotFound,
+		Err:  errors.ErrPlanNotFound,
+	}
+	ErrPlanExists = Error{
+		Code: http.StatusConflict,
+		Err:  errors.ErrPlanExists,
+	}
 )
@@ -21,10 +21,11 @@ import (
 	goerrors "errors"
 	"fmt"
 	"net/http"
-	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/websocket"
 
 	"kubevulpes/api/errors"
 	validatorutil "kubevulpes/api/validator"
@@ -144,6 +145,18 @@ func ShouldBindAny(c *gin.Context, jsonObject interface{}, uriObject interface{}
 	return nil
 }
 
+// UpgradeWebsocket 升级为 websocket 连接，token 通过 Sec-WebSocket-Protocol 传递，需要原样返回
+func UpgradeWebsocket(c *gin.Context) (*websocket.Conn, error) {
+	upgrader := &websocket.Upgrader{
+		HandshakeTimeout: time.Second * 2,
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+		Subprotocols: []string{c.GetHeader("Sec-WebSocket-Protocol")},
+	}
+	return upgrader.Upgrade(c.Writer, c.Request, nil)
+}
+
 const (
 	userKey = "user"
 )
@@ -173,33 +186,10 @@ func SetUserToContext(c *gin.Context, user *model.User) {
 	c.Set(userKey, user)
 }
 
-func GetObjectFromRequest(c *gin.Context) (string, string, bool) {
-	return getObjectFromRequest(c.Request.URL.Path)
-}
-
-// getObjectFromRequest cuts and returns the object from the request path.
-// e.g. /api/vuples/clusters/1 -> "clusters" "1" true
-
-func getObjectFromRequest(path string) (obj, sid string, ok bool) {
-	// must start with /
-	l := len(path)
-	if l == 0 || path[0] != '/' {
-		return
-	}
-	subs := strings.Split(path[1:l], "/")
-	l = len(subs)
-	if l < 3 || subs[1] != "vuples" {
-		return
-	}
-	if l == 3 {
-		// e.g. /api/vuples/clusters -> "clusters" "" true
-		return subs[2], "", subs[2] != ""
-	}
-	return subs[2], subs[3], subs[2] != "" && subs[3] != ""
-}
-
 const (
-	objIDsKey = "objIDs"
+	objIDsKey     = "objIDs"
+	objNamesKey   = "objNames"
+	namespacesKey = "namespaces"
 )
 
 func SetIdRangeContext(c *gin.Context, ids []int64) {
@@ -216,9 +206,40 @@ func GetIdRangeFromListReq(ctx context.Context) (exists bool, ids []int64) {
 	return
 }
 
+// SetNameRangeContext 设置列表接口可以返回的对象名称，与 id 范围取并集
+func SetNameRangeContext(c *gin.Context, names []string) {
+	c.Set(objNamesKey, names)
+}
+
+func GetNameRangeFromListReq(ctx context.Context) (exists bool, names []string) {
+	val := ctx.Value(objNamesKey)
+	if val == nil {
+		return
+	}
+
+	names, exists = val.([]string)
+	return
+}
+
+// SetNamespaceRangeContext 设置 kubernetes 资源列表可以返回的命名空间
+func SetNamespaceRangeContext(c *gin.Context, namespaces []string) {
+	c.Set(namespacesKey, namespaces)
+}
+
+func GetNamespaceRangeFromListReq(ctx context.Context) (exists bool, namespaces []string) {
+	val := ctx.Value(namespacesKey)
+	if val == nil {
+		return
+	}
+
+	namespaces, exists = val.([]string)
+	return
+}
+
 const (
 	ResponseCodeKey = "response_code"
 	RawErrorKey     = "raw_error"
+	AuditEventKey   = "audit_event"
 )
 
 type ctxBind struct {
@@ -241,6 +262,22 @@ func (cb *ctxBind) withRawError(err error) *ctxBind {
 	return cb
 }
 
+// SetResponseCode puts the response code into the HTTP context, it is used by handlers not responding with Response.
+func SetResponseCode(c *gin.Context, code int) {
+	contextBind(c).withResponseCode(code)
+}
+
+// SetAuditEvent puts the operation details into the HTTP context, it is recorded in audit event.
+func SetAuditEvent(c *gin.Context, event string) {
+	c.Set(AuditEventKey, event)
+}
+
+// GetAuditEvent gets the operation details from the HTTP context.
+func GetAuditEvent(ctx context.Context) string {
+	event, _ := ctx.Value(AuditEventKey).(string)
+	return event
+}
+
 // GetResponseCode gets the response code from the HTTP context.
 func GetResponseCode(ctx context.Context) (code int) {
 	val := ctx.Value(ResponseCodeKey)
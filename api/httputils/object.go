@@ -0,0 +1,171 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httputils
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"kubevulpes/pkg/db/model"
+)
+
+// RequestObject 请求访问的对象，用于鉴权和审计
+type RequestObject struct {
+	Type string
+	// 平台对象为对象的 id，kubernetes 资源为集群名称
+	SID string
+	// kubernetes 资源所在的命名空间，平台对象和集群级别的资源为空
+	Namespace string
+	// 是否为列表接口，列表接口根据权限过滤返回的对象
+	List bool
+	// 由接口自身根据用户的权限鉴权，例如签发用户的 kubeConfig
+	Self bool
+}
+
+// IsKube 判断请求的对象是否为 kubernetes 资源
+func (o RequestObject) IsKube() bool {
+	return model.IsKubeObject(model.ObjectType(o.Type))
+}
+
+func GetObjectFromRequest(c *gin.Context) (RequestObject, bool) {
+	object, ok := getObjectFromRequest(c.Request.URL)
+	object.List = object.List && c.Request.Method == http.MethodGet
+	return object, ok
+}
+
+// getObjectFromRequest cuts and returns the object from the request path.
+// e.g. /api/vulpes/clusters/1 -> {clusters 1} true
+func getObjectFromRequest(u *url.URL) (object RequestObject, ok bool) {
+	path := u.Path
+	// must start with /
+	l := len(path)
+	if l == 0 || path[0] != '/' {
+		return
+	}
+	subs := strings.Split(path[1:l], "/")
+	l = len(subs)
+	if l < 3 || subs[1] != "vulpes" {
+		return
+	}
+	if l == 3 {
+		// e.g. /api/vulpes/clusters -> {clusters} true
+		return RequestObject{Type: subs[2], List: true}, subs[2] != ""
+	}
+	if l == 6 && subs[2] == model.ObjectCluster.String() && subs[4] == "kubeconfig" && subs[5] == "me" {
+		// e.g. /api/vulpes/clusters/1/kubeconfig/me -> {clusters 1} true
+		return RequestObject{Type: subs[2], SID: subs[3], Self: true}, subs[3] != ""
+	}
+	if l > 5 && subs[2] == model.ObjectCluster.String() && subs[4] == "proxy" {
+		// e.g. /api/vulpes/clusters/1/proxy/apis/apps/v1/namespaces/default/deployments -> {deployments 1 default} true
+		return proxyObjectFromPath(subs[3], subs[5:]), subs[3] != ""
+	}
+	if object, ok = kubeObjectFromPath(subs, u.Query()); ok {
+		// e.g. /api/vulpes/clusters/c1/namespaces/default/deployments/foo/scale -> {deployments c1 default} true
+		return object, subs[3] != ""
+	}
+	return RequestObject{Type: subs[2], SID: subs[3]}, subs[2] != "" && subs[3] != ""
+}
+
+// kubeObjectFromPath kubernetes 资源使用资源的复数名称作为对象类型，sid 为集群名称
+// 集群级别的其他接口（例如 apply）仍然按照集群鉴权
+func kubeObjectFromPath(subs []string, query url.Values) (RequestObject, bool) {
+	if len(subs) < 5 || subs[2] != model.ObjectCluster.String() {
+		return RequestObject{}, false
+	}
+	cluster, rest := subs[3], subs[4:]
+
+	switch rest[0] {
+	case "namespaces":
+		if len(rest) == 1 {
+			return RequestObject{Type: rest[0], SID: cluster, List: true}, true
+		}
+		ns := rest[1]
+		switch {
+		case len(rest) == 2 || rest[2] == "usage":
+			// 命名空间本身以及命名空间的资源使用量
+			return RequestObject{Type: rest[0], SID: cluster, Namespace: ns}, true
+		case rest[2] == "resources":
+			// e.g. namespaces/default/resources/apps/v1/deployments
+			if len(rest) < 6 {
+				return RequestObject{}, false
+			}
+			return RequestObject{Type: rest[5], SID: cluster, Namespace: ns, List: len(rest) == 6}, true
+		default:
+			return RequestObject{Type: rest[2], SID: cluster, Namespace: ns, List: len(rest) == 3}, true
+		}
+	case "nodes":
+		return RequestObject{Type: rest[0], SID: cluster, List: len(rest) == 1}, true
+	case "resources":
+		// e.g. resources/core/v1/pods
+		if len(rest) < 4 {
+			return RequestObject{}, false
+		}
+		return RequestObject{Type: rest[3], SID: cluster, List: len(rest) == 4}, true
+	case "events":
+		return RequestObject{Type: rest[0], SID: cluster, Namespace: eventsNamespace(query), List: true}, true
+	case "usage":
+		// 集群的资源使用量由节点的使用量汇总
+		return RequestObject{Type: model.ObjectNode.String(), SID: cluster}, true
+	case "apply":
+		// manifest 中的对象由接口按照对象的资源类型和命名空间逐个鉴权
+		return RequestObject{Type: model.ObjectCluster.String(), SID: cluster, Self: true}, true
+	case "terminal":
+		// 容器终端按照 pod 鉴权
+		return RequestObject{Type: model.ObjectPod.String(), SID: cluster, Namespace: query.Get("namespace")}, true
+	}
+	return RequestObject{}, false
+}
+
+// eventsNamespace 集群级别对象的事件需要查询全部命名空间，此时按照不限定命名空间鉴权
+// 没有全部命名空间权限的用户只返回有权限的命名空间内的事件
+func eventsNamespace(query url.Values) string {
+	namespaced, _ := strconv.ParseBool(query.Get("namespaced"))
+	if (len(query.Get("uid")) != 0 || len(query.Get("name")) != 0) && !namespaced {
+		return ""
+	}
+	return query.Get("namespace")
+}
+
+// proxyObjectFromPath 代理请求由集群内的 RBAC 鉴权，对象类型只用于审计，sid 为集群的 id
+// 非资源请求（例如 /version）按照集群记录
+func proxyObjectFromPath(cluster string, rest []string) RequestObject {
+	object := RequestObject{Type: model.ObjectCluster.String(), SID: cluster, Self: true}
+	switch {
+	case rest[0] == "api" && len(rest) > 2:
+		// e.g. api/v1/pods
+		rest = rest[2:]
+	case rest[0] == "apis" && len(rest) > 3:
+		// e.g. apis/apps/v1/deployments
+		rest = rest[3:]
+	default:
+		return object
+	}
+
+	if rest[0] == "namespaces" && len(rest) > 2 {
+		object.Namespace, rest = rest[1], rest[2:]
+	} else if rest[0] == "namespaces" && len(rest) == 2 {
+		object.Namespace = rest[1]
+	}
+	if rest[0] != "" {
+		object.Type = rest[0]
+	}
+	return object
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httputils
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestGetObjectFromRequest(t *testing.T) {
+	cases := []struct {
+		name     string
+		path     string
+		expected RequestObject
+		ok       bool
+	}{
+		{name: "empty path", path: ""},
+		{name: "not vulpes api", path: "/api/v1/pods"},
+		{name: "too short", path: "/api/vulpes"},
+		{name: "list objects", path: "/api/vulpes/clusters", expected: RequestObject{Type: "clusters", List: true}, ok: true},
+		{name: "empty object type", path: "/api/vulpes/", expected: RequestObject{List: true}},
+		{name: "get object", path: "/api/vulpes/users/1", expected: RequestObject{Type: "users", SID: "1"}, ok: true},
+		{name: "empty object id", path: "/api/vulpes/users/", expected: RequestObject{Type: "users"}},
+		{name: "get cluster", path: "/api/vulpes/clusters/1", expected: RequestObject{Type: "clusters", SID: "1"}, ok: true},
+		{name: "cluster subresource", path: "/api/vulpes/clusters/1/restore", expected: RequestObject{Type: "clusters", SID: "1"}, ok: true},
+
+		// 用户自己的 kubeConfig 和代理请求由接口自身鉴权
+		{name: "user kubeconfig", path: "/api/vulpes/clusters/1/kubeconfig/me", expected: RequestObject{Type: "clusters", SID: "1", Self: true}, ok: true},
+		{name: "proxy non-resource", path: "/api/vulpes/clusters/1/proxy/version", expected: RequestObject{Type: "clusters", SID: "1", Self: true}, ok: true},
+		{name: "proxy core resources", path: "/api/vulpes/clusters/1/proxy/api/v1/pods", expected: RequestObject{Type: "pods", SID: "1", Self: true}, ok: true},
+		{name: "proxy namespaced resource", path: "/api/vulpes/clusters/1/proxy/apis/apps/v1/namespaces/default/deployments/foo", expected: RequestObject{Type: "deployments", SID: "1", Namespace: "default", Self: true}, ok: true},
+		{name: "proxy namespace", path: "/api/vulpes/clusters/1/proxy/api/v1/namespaces/default", expected: RequestObject{Type: "namespaces", SID: "1", Namespace: "default", Self: true}, ok: true},
+		{name: "proxy api group", path: "/api/vulpes/clusters/1/proxy/apis/apps/v1", expected: RequestObject{Type: "clusters", SID: "1", Self: true}, ok: true},
+
+		// kubernetes 资源的 sid 为集群名称
+		{name: "list namespaces", path: "/api/vulpes/clusters/c1/namespaces", expected: RequestObject{Type: "namespaces", SID: "c1", List: true}, ok: true},
+		{name: "get namespace", path: "/api/vulpes/clusters/c1/namespaces/default", expected: RequestObject{Type: "namespaces", SID: "c1", Namespace: "default"}, ok: true},
+		{name: "namespace usage", path: "/api/vulpes/clusters/c1/namespaces/default/usage", expected: RequestObject{Type: "namespaces", SID: "c1", Namespace: "default"}, ok: true},
+		{name: "list deployments", path: "/api/vulpes/clusters/c1/namespaces/default/deployments", expected: RequestObject{Type: "deployments", SID: "c1", Namespace: "default", List: true}, ok: true},
+		{name: "scale deployment", path: "/api/vulpes/clusters/c1/namespaces/default/deployments/foo/scale", expected: RequestObject{Type: "deployments", SID: "c1", Namespace: "default"}, ok: true},
+		{name: "list namespaced resources", path: "/api/vulpes/clusters/c1/namespaces/default/resources/apps/v1/deployments", expected: RequestObject{Type: "deployments", SID: "c1", Namespace: "default", List: true}, ok: true},
+		{name: "get namespaced resource", path: "/api/vulpes/clusters/c1/namespaces/default/resources/apps/v1/deployments/foo", expected: RequestObject{Type: "deployments", SID: "c1", Namespace: "default"}, ok: true},
+		{name: "incomplete namespaced resources", path: "/api/vulpes/clusters/c1/namespaces/default/resources/apps", expected: RequestObject{Type: "clusters", SID: "c1"}, ok: true},
+		{name: "list nodes", path: "/api/vulpes/clusters/c1/nodes", expected: RequestObject{Type: "nodes", SID: "c1", List: true}, ok: true},
+		{name: "get node", path: "/api/vulpes/clusters/c1/nodes/node1", expected: RequestObject{Type: "nodes", SID: "c1"}, ok: true},
+		{name: "list cluster resources", path: "/api/vulpes/clusters/c1/resources/core/v1/persistentvolumes", expected: RequestObject{Type: "persistentvolumes", SID: "c1", List: true}, ok: true},
+		{name: "get cluster resource", path: "/api/vulpes/clusters/c1/resources/core/v1/persistentvolumes/pv1", expected: RequestObject{Type: "persistentvolumes", SID: "c1"}, ok: true},
+		{name: "cluster usage", path: "/api/vulpes/clusters/c1/usage", expected: RequestObject{Type: "nodes", SID: "c1"}, ok: true},
+		{name: "apply", path: "/api/vulpes/clusters/c1/apply", expected: RequestObject{Type: "clusters", SID: "c1", Self: true}, ok: true},
+		{name: "terminal", path: "/api/vulpes/clusters/c1/terminal?namespace=default&pod=foo", expected: RequestObject{Type: "pods", SID: "c1", Namespace: "default"}, ok: true},
+
+		// 集群级别对象的事件需要全部命名空间的权限
+		{name: "namespace events", path: "/api/vulpes/clusters/c1/events?namespace=default", expected: RequestObject{Type: "events", SID: "c1", Namespace: "default", List: true}, ok: true},
+		{name: "namespaced object events", path: "/api/vulpes/clusters/c1/events?namespace=default&name=foo&namespaced=true", expected: RequestObject{Type: "events", SID: "c1", Namespace: "default", List: true}, ok: true},
+		{name: "cluster object events by name", path: "/api/vulpes/clusters/c1/events?namespace=default&name=node1", expected: RequestObject{Type: "events", SID: "c1", List: true}, ok: true},
+		{name: "cluster object events by uid", path: "/api/vulpes/clusters/c1/events?uid=xxx&namespaced=false", expected: RequestObject{Type: "events", SID: "c1", List: true}, ok: true},
+		{name: "all events", path: "/api/vulpes/clusters/c1/events", expected: RequestObject{Type: "events", SID: "c1", List: true}, ok: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			u, err := url.Parse(tc.path)
+			if err != nil {
+				t.Fatalf("failed to parse path %q: %v", tc.path, err)
+			}
+			object, ok := getObjectFromRequest(u)
+			if ok != tc.ok {
+				t.Fatalf("expected ok %v, got %v", tc.ok, ok)
+			}
+			if object != tc.expected {
+				t.Fatalf("expected object %+v, got %+v", tc.expected, object)
+			}
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/gin-contrib/requestid"
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 
 	"kubevulpes/api/httputils"
 	option "kubevulpes/cmd/app/options"
@@ -59,8 +60,10 @@ func Audit(o *option.Options) gin.HandlerFunc {
 // asyncAudit audits the request asynchronously.
 // It should be called in a goroutine.
 func (w *auditWriter) asyncAudit(c *gin.Context) {
+	// websocket 终端会话需要审计，会话录制通过 request id 关联审计记录
 	if c.Request.Method == http.MethodGet &&
-		c.Writer.Status() != http.StatusUnauthorized {
+		c.Writer.Status() != http.StatusUnauthorized &&
+		!websocket.IsWebSocketUpgrade(c.Request) {
 		return
 	}
 
@@ -69,7 +72,7 @@ func (w *auditWriter) asyncAudit(c *gin.Context) {
 		userName = user.Name
 	}
 
-	obj, _, ok := httputils.GetObjectFromRequest(c)
+	obj, ok := httputils.GetObjectFromRequest(c)
 	if !ok {
 		return
 	}
@@ -80,8 +83,9 @@ func (w *auditWriter) asyncAudit(c *gin.Context) {
 		IP:         c.ClientIP(),
 		Operator:   userName,
 		Path:       c.Request.RequestURI,
-		ObjectType: model.ObjectType(obj),
+		ObjectType: model.ObjectType(obj.Type),
 		Status:     getAuditStatus(c),
+		Event:      httputils.GetAuditEvent(c),
 	}
 	if err := w.opts.Factory.Audit().Create(context.TODO(), audit); err != nil {
 		klog.Errorf("failed to create audit record [%s]: %v", audit.String(), err)
@@ -19,9 +19,11 @@ package middleware
 import (
 	"fmt"
 	"net/http"
+	"path"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 
 	"kubevulpes/api/errors"
 	"kubevulpes/api/httputils"
@@ -60,7 +62,8 @@ func Authentication(o *option.Options) gin.HandlerFunc {
 }
 
 func validate(c *gin.Context, o *option.Options, keyBytes []byte) error {
-	token, err := extractToken(c, false)
+	// websocket 无法设置 Authorization 请求头，token 通过 Sec-WebSocket-Protocol 传递
+	token, err := extractToken(c, websocket.IsWebSocketUpgrade(c.Request))
 	if err != nil {
 		return err
 	}
@@ -122,6 +125,26 @@ var operationsMap = map[string]model.Operation{
 	http.MethodDelete: model.OpDelete,
 }
 
+// 子资源使用独立的操作类型，不根据 HTTP method 判断
+// 驱逐会删除 pod，按照删除鉴权
+var subResourceOperationsMap = map[string]model.Operation{
+	"terminal": model.OpExec,
+	"ssh":      model.OpExec,
+	"eviction": model.OpDelete,
+}
+
+// getOperation 按照路由模板判断子资源，避免名称恰好为子资源的对象（例如名为 eviction 的 pod）被误判
+func getOperation(c *gin.Context) model.Operation {
+	route := c.FullPath()
+	if len(route) == 0 {
+		route = c.Request.URL.Path
+	}
+	if op, ok := subResourceOperationsMap[path.Base(route)]; ok {
+		return op
+	}
+	return operationsMap[c.Request.Method]
+}
+
 // Authorization 鉴权
 func Authorization(o *option.Options) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -143,32 +166,38 @@ func Authorization(o *option.Options) gin.HandlerFunc {
 			return
 		}
 
-		obj, id, ok := httputils.GetObjectFromRequest(c)
-		if !ok {
+		obj, ok := httputils.GetObjectFromRequest(c)
+		if !ok || obj.Self {
 			return
 		}
 
-		op := operationsMap[c.Request.Method]
+		op := getOperation(c)
 		// load policy for consistency
 		// ref: https://github.com/casbin/casbin/issues/679#issuecomment-761525328
 		if err := o.Enforcer.LoadPolicy(); err != nil {
 			httputils.AbortFailedWithCode(c, http.StatusInternalServerError, err)
 			return
 		}
-		ok, err = o.Enforcer.Enforce(user.Name, obj, id, op.String())
+		ok, err = o.Enforcer.Enforce(user.Name, obj.Type, obj.SID, op.String(), obj.Namespace)
 		if err != nil {
 			httputils.AbortFailedWithCode(c, http.StatusMethodNotAllowed, err)
 			return
 		}
-		if !ok {
-			httputils.AbortFailedWithCode(c, http.StatusForbidden, fmt.Errorf("无操作权限"))
+		if ok {
+			return
 		}
-		if id != "" {
+		if !obj.List {
+			httputils.AbortFailedWithCode(c, http.StatusForbidden, fmt.Errorf("无操作权限"))
 			return
 		}
-		// this is a list API
-		if err := ctrlutil.SetIdRangeContext(c, o.Enforcer, user, obj); err != nil {
+		// this is a list API, 只返回有权限的对象
+		scoped, err := ctrlutil.SetIdRangeContext(c, o.Enforcer, user, obj)
+		if err != nil {
 			httputils.AbortFailedWithCode(c, http.StatusInternalServerError, err)
+			return
+		}
+		if !scoped {
+			httputils.AbortFailedWithCode(c, http.StatusForbidden, fmt.Errorf("无操作权限"))
 		}
 	}
 }
@@ -0,0 +1,86 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"kubevulpes/pkg/db/model"
+)
+
+func TestGetOperation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var op model.Operation
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		op = getOperation(c)
+	})
+	handler := func(c *gin.Context) {}
+	kubeRoute := engine.Group("/api/vulpes/clusters/:cluster")
+	{
+		kubeRoute.GET("/terminal", handler)
+		kubeRoute.GET("/namespaces/:namespace/pods", handler)
+		kubeRoute.GET("/namespaces/:namespace/pods/:name", handler)
+		kubeRoute.DELETE("/namespaces/:namespace/pods/:name", handler)
+		kubeRoute.POST("/namespaces/:namespace/pods/:name/eviction", handler)
+		for _, resource := range []string{"deployments", "statefulsets", "daemonsets"} {
+			kubeRoute.PUT("/namespaces/:namespace/"+resource+"/:name/scale", handler)
+			kubeRoute.PUT("/namespaces/:namespace/"+resource+"/:name/restart", handler)
+		}
+		kubeRoute.PUT("/namespaces/:namespace/deployments/:name/pause", handler)
+		kubeRoute.PUT("/namespaces/:namespace/deployments/:name/resume", handler)
+	}
+	engine.GET("/api/vulpes/hosts/:hostId/ssh", handler)
+
+	cases := []struct {
+		name     string
+		method   string
+		path     string
+		expected model.Operation
+	}{
+		{name: "list pods", method: http.MethodGet, path: "/api/vulpes/clusters/c1/namespaces/default/pods", expected: model.OpRead},
+		{name: "get pod", method: http.MethodGet, path: "/api/vulpes/clusters/c1/namespaces/default/pods/foo", expected: model.OpRead},
+		{name: "delete pod", method: http.MethodDelete, path: "/api/vulpes/clusters/c1/namespaces/default/pods/foo", expected: model.OpDelete},
+		{name: "evict pod", method: http.MethodPost, path: "/api/vulpes/clusters/c1/namespaces/default/pods/foo/eviction", expected: model.OpDelete},
+		{name: "scale deployment", method: http.MethodPut, path: "/api/vulpes/clusters/c1/namespaces/default/deployments/foo/scale", expected: model.OpUpdate},
+		{name: "scale statefulset", method: http.MethodPut, path: "/api/vulpes/clusters/c1/namespaces/default/statefulsets/foo/scale", expected: model.OpUpdate},
+		{name: "restart deployment", method: http.MethodPut, path: "/api/vulpes/clusters/c1/namespaces/default/deployments/foo/restart", expected: model.OpUpdate},
+		{name: "restart daemonset", method: http.MethodPut, path: "/api/vulpes/clusters/c1/namespaces/default/daemonsets/foo/restart", expected: model.OpUpdate},
+		{name: "pause deployment", method: http.MethodPut, path: "/api/vulpes/clusters/c1/namespaces/default/deployments/foo/pause", expected: model.OpUpdate},
+		{name: "resume deployment", method: http.MethodPut, path: "/api/vulpes/clusters/c1/namespaces/default/deployments/foo/resume", expected: model.OpUpdate},
+		{name: "pod terminal", method: http.MethodGet, path: "/api/vulpes/clusters/c1/terminal?namespace=default&pod=foo", expected: model.OpExec},
+		{name: "host ssh", method: http.MethodGet, path: "/api/vulpes/hosts/1/ssh", expected: model.OpExec},
+		// 名称与子资源相同的对象仍然按照 HTTP method 鉴权
+		{name: "delete pod named terminal", method: http.MethodDelete, path: "/api/vulpes/clusters/c1/namespaces/default/pods/terminal", expected: model.OpDelete},
+		{name: "get pod named eviction", method: http.MethodGet, path: "/api/vulpes/clusters/c1/namespaces/default/pods/eviction", expected: model.OpRead},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			op = ""
+			engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tc.method, tc.path, nil))
+			if op != tc.expected {
+				t.Fatalf("expected operation %q, got %q", tc.expected, op)
+			}
+		})
+	}
+}
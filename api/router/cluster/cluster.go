@@ -36,9 +36,20 @@ func (r *clusterRouter) initRouter(httpEngine *gin.Engine) {
 	clusterRoute := httpEngine.Group("/api/vulpes/clusters")
 	{
 		clusterRoute.POST("", r.createCluster)
+		clusterRoute.POST("/kubeconfig/preview", r.previewKubeConfig)
+		clusterRoute.POST("/import", r.importClusters)
 		clusterRoute.GET("", r.listCluster)
-		clusterRoute.GET("/:clusterId", r.getCluster)
-		clusterRoute.DELETE("", r.deleteCluster)
-		clusterRoute.PUT("/:clusterId", r.updateCluster)
+		clusterRoute.GET("/deleted", r.listDeletedClusters)
+		clusterRoute.GET("/:cluster", r.getCluster)
+		clusterRoute.DELETE("/:cluster", r.deleteCluster)
+		clusterRoute.POST("/:cluster/restore", r.restoreCluster)
+		clusterRoute.PUT("/:cluster", r.updateCluster)
+		clusterRoute.PUT("/:cluster/protection", r.protectCluster)
+		clusterRoute.PUT("/:cluster/kubeconfig", r.updateClusterKubeConfig)
+		clusterRoute.GET("/:cluster/kubeconfig/me", r.getUserKubeConfig)
+		clusterRoute.Any("/:cluster/proxy/*path", r.proxy)
+		clusterRoute.GET("/:cluster/status_records", r.listClusterStatusRecords)
+		clusterRoute.GET("/:cluster/informers", r.getClusterInformers)
+		clusterRoute.PUT("/:cluster/informers", r.updateClusterInformers)
 	}
 }
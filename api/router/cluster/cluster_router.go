@@ -24,7 +24,7 @@ import (
 )
 
 type IdMeta struct {
-	ClusterId int64 `uri:"clusterId" binding:"required"`
+	ClusterId int64 `uri:"cluster" binding:"required"`
 }
 
 func (cr *clusterRouter) createCluster(c *gin.Context) {
@@ -45,6 +45,44 @@ func (cr *clusterRouter) createCluster(c *gin.Context) {
 	httputils.SetSuccess(c, r)
 }
 
+func (cr *clusterRouter) previewKubeConfig(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		req types.PreviewKubeConfigRequest
+		err error
+	)
+	if err = c.ShouldBindJSON(&req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = cr.c.Cluster().PreviewKubeConfig(c, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (cr *clusterRouter) importClusters(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		req types.ImportClustersRequest
+		err error
+	)
+	if err = c.ShouldBindJSON(&req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = cr.c.Cluster().Import(c, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
 func (cr *clusterRouter) updateCluster(c *gin.Context) {
 	r := httputils.NewResponse()
 	var (
@@ -70,6 +108,85 @@ func (cr *clusterRouter) updateCluster(c *gin.Context) {
 	httputils.SetSuccess(c, r)
 }
 
+func (cr *clusterRouter) protectCluster(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		idMeta IdMeta
+		req    types.ProtectClusterRequest
+		err    error
+	)
+	if err = httputils.ShouldBindAny(c, &req, &idMeta, nil); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if err = cr.c.Cluster().Protect(c, idMeta.ClusterId, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (cr *clusterRouter) updateClusterKubeConfig(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		idMeta IdMeta
+		req    types.UpdateClusterKubeConfigRequest
+		err    error
+	)
+	if err = httputils.ShouldBindAny(c, &req, &idMeta, nil); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if err = cr.c.Cluster().UpdateKubeConfig(c, idMeta.ClusterId, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (cr *clusterRouter) getClusterInformers(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		idMeta IdMeta
+		err    error
+	)
+	if err = c.ShouldBindUri(&idMeta); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = cr.c.Cluster().GetInformers(c, idMeta.ClusterId); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (cr *clusterRouter) updateClusterInformers(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		idMeta IdMeta
+		req    types.UpdateClusterInformersRequest
+		err    error
+	)
+	if err = httputils.ShouldBindAny(c, &req, &idMeta, nil); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if err = cr.c.Cluster().UpdateInformers(c, idMeta.ClusterId, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
 func (cr *clusterRouter) deleteCluster(c *gin.Context) {
 	r := httputils.NewResponse()
 
@@ -91,6 +208,87 @@ func (cr *clusterRouter) deleteCluster(c *gin.Context) {
 	httputils.SetSuccess(c, r)
 }
 
+// restoreCluster 恢复保留期内已删除的集群
+func (cr *clusterRouter) restoreCluster(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		idMeta IdMeta
+		err    error
+	)
+	if err = c.ShouldBindUri(&idMeta); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if err = cr.c.Cluster().Restore(c, idMeta.ClusterId); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+// getUserKubeConfig 按照当前用户的权限签发集群的 kubeConfig
+func (cr *clusterRouter) getUserKubeConfig(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		idMeta IdMeta
+		err    error
+	)
+	if err = c.ShouldBindUri(&idMeta); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = cr.c.Cluster().UserKubeConfig(c, idMeta.ClusterId); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+// proxy 将请求转发至集群的 apiserver，kubectl 等工具可以将 /api/vulpes/clusters/:cluster/proxy 作为集群地址
+func (cr *clusterRouter) proxy(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		idMeta IdMeta
+		err    error
+	)
+	if err = c.ShouldBindUri(&idMeta); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	handler, err := cr.c.Cluster().Proxy(c, idMeta.ClusterId, c.Param("path"))
+	if err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	handler.ServeHTTP(c.Writer, c.Request)
+	httputils.SetResponseCode(c, c.Writer.Status())
+}
+
+func (cr *clusterRouter) listDeletedClusters(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		err         error
+		listOptions types.ListOptions
+	)
+	if err = httputils.ShouldBindAny(c, nil, nil, &listOptions); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = cr.c.Cluster().ListDeleted(c, &listOptions); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
 func (cr *clusterRouter) getCluster(c *gin.Context) {
 	r := httputils.NewResponse()
 
@@ -129,3 +327,23 @@ func (cr *clusterRouter) listCluster(c *gin.Context) {
 
 	httputils.SetSuccess(c, r)
 }
+
+func (cr *clusterRouter) listClusterStatusRecords(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		idMeta      IdMeta
+		listOptions types.ListOptions
+		err         error
+	)
+	if err = httputils.ShouldBindAny(c, nil, &idMeta, &listOptions); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = cr.c.Cluster().ListStatusRecords(c, idMeta.ClusterId, &listOptions); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
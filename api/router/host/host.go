@@ -0,0 +1,53 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package host
+
+import (
+	"github.com/gin-gonic/gin"
+
+	option "kubevulpes/cmd/app/options"
+	"kubevulpes/pkg/controller"
+)
+
+type hostRouter struct {
+	c controller.VuplesInterface
+}
+
+func NewRouter(o *option.Options) {
+	r := &hostRouter{c: o.Controller}
+	r.initRouter(o.HttpEngine)
+}
+
+func (h *hostRouter) initRouter(httpEngine *gin.Engine) {
+	hostRoute := httpEngine.Group("/api/vulpes/hosts")
+	{
+		hostRoute.POST("", h.createHost)
+		hostRoute.GET("", h.listHosts)
+		hostRoute.GET("/:hostId", h.getHost)
+		hostRoute.PUT("/:hostId", h.updateHost)
+		hostRoute.DELETE("/:hostId", h.deleteHost)
+		// 登陆主机采集系统信息
+		hostRoute.POST("/:hostId/check", h.checkHost)
+
+		// 节点终端，通过 websocket 交互
+		hostRoute.GET("/:hostId/ssh", h.ssh)
+
+		// 终端会话录制
+		hostRoute.GET("/:hostId/sessions", h.listSessions)
+		hostRoute.GET("/:hostId/sessions/:sessionId", h.getSession)
+	}
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package host
+
+import (
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"k8s.io/klog/v2"
+
+	"kubevulpes/api/httputils"
+	"kubevulpes/pkg/controller/host"
+	"kubevulpes/pkg/types"
+)
+
+type HostMeta struct {
+	HostId int64 `uri:"hostId" binding:"required"`
+}
+
+type SessionMeta struct {
+	HostId    int64 `uri:"hostId" binding:"required"`
+	SessionId int64 `uri:"sessionId" binding:"required"`
+}
+
+func (h *hostRouter) createHost(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		req types.CreateHostRequest
+		err error
+	)
+	if err = c.ShouldBindJSON(&req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = h.c.Host().Create(c, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (h *hostRouter) updateHost(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		opt HostMeta
+		req types.UpdateHostRequest
+		err error
+	)
+	if err = httputils.ShouldBindAny(c, &req, &opt, nil); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if err = h.c.Host().Update(c, opt.HostId, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (h *hostRouter) deleteHost(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		opt HostMeta
+		err error
+	)
+	if err = c.ShouldBindUri(&opt); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if err = h.c.Host().Delete(c, opt.HostId); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (h *hostRouter) getHost(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		opt HostMeta
+		err error
+	)
+	if err = c.ShouldBindUri(&opt); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = h.c.Host().Get(c, opt.HostId); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (h *hostRouter) listHosts(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		listOptions types.ListHostOptions
+		err         error
+	)
+	if err = c.ShouldBindQuery(&listOptions); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = h.c.Host().List(c, &listOptions); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (h *hostRouter) checkHost(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		opt HostMeta
+		err error
+	)
+	if err = c.ShouldBindUri(&opt); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = h.c.Host().Check(c, opt.HostId); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+// ssh 升级为 websocket 之后错误输出到终端中
+func (h *hostRouter) ssh(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		opt HostMeta
+		err error
+	)
+	if err = c.ShouldBindUri(&opt); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	user, err := httputils.GetUserFromRequest(c)
+	if err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	conn, err := httputils.UpgradeWebsocket(c)
+	if err != nil {
+		klog.Errorf("failed to upgrade ssh connection: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	if err = h.c.Host().SSH(c, opt.HostId, conn, host.SessionMeta{
+		Operator:  user.Name,
+		RequestId: requestid.Get(c),
+	}); err != nil {
+		_ = conn.WriteMessage(websocket.BinaryMessage, []byte(err.Error()))
+	}
+}
+
+func (h *hostRouter) listSessions(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		opt         HostMeta
+		listOptions types.ListOptions
+		err         error
+	)
+	if err = httputils.ShouldBindAny(c, nil, &opt, &listOptions); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = h.c.Host().ListSessions(c, opt.HostId, &listOptions); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (h *hostRouter) getSession(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		opt SessionMeta
+		err error
+	)
+	if err = c.ShouldBindUri(&opt); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = h.c.Host().GetSession(c, opt.HostId, opt.SessionId); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"github.com/gin-gonic/gin"
+
+	option "kubevulpes/cmd/app/options"
+	"kubevulpes/pkg/controller"
+	"kubevulpes/pkg/controller/kube"
+)
+
+type kubeRouter struct {
+	c controller.VuplesInterface
+}
+
+func NewRouter(o *option.Options) {
+	r := &kubeRouter{c: o.Controller}
+	r.initRouter(o.HttpEngine)
+}
+
+func (k *kubeRouter) initRouter(httpEngine *gin.Engine) {
+	kubeRoute := httpEngine.Group("/api/vulpes/clusters/:cluster")
+	{
+		// 集群级别的资源
+		kubeRoute.GET("/nodes", k.listClusterObjects(kube.ResourceNodes))
+		kubeRoute.GET("/nodes/:name", k.getClusterObject(kube.ResourceNodes))
+		kubeRoute.GET("/namespaces", k.listClusterObjects(kube.ResourceNamespaces))
+		kubeRoute.GET("/namespaces/:namespace", k.getNamespace)
+
+		// 资源使用情况，使用量来自 metrics-server
+		kubeRoute.GET("/usage", k.getClusterUsage)
+		kubeRoute.GET("/nodes/:name/usage", k.getNodeUsage)
+		kubeRoute.GET("/namespaces/:namespace/usage", k.getNamespaceUsage)
+
+		// 事件，支持按照对象、命名空间和集群查询
+		kubeRoute.GET("/events", k.listEvents)
+
+		// 容器终端，通过 websocket 交互
+		kubeRoute.GET("/terminal", k.terminal)
+
+		// 命名空间级别的资源
+		for _, resource := range kube.NamespacedResources() {
+			kubeRoute.GET("/namespaces/:namespace/"+resource, k.listObjects(resource))
+			kubeRoute.GET("/namespaces/:namespace/"+resource+"/:name", k.getObject(resource))
+		}
+
+		// 集群缓存的任意资源，包括 CRD，核心组的 group 为 core
+		kubeRoute.GET("/resources/:group/:version/:resource", k.listResources)
+		kubeRoute.GET("/resources/:group/:version/:resource/:name", k.getResource)
+		kubeRoute.GET("/namespaces/:namespace/resources/:group/:version/:resource", k.listResources)
+		kubeRoute.GET("/namespaces/:namespace/resources/:group/:version/:resource/:name", k.getResource)
+
+		// 通过 server-side apply 应用 yaml，支持 dry-run
+		kubeRoute.POST("/apply", k.apply)
+
+		// 工作负载操作
+		for _, resource := range kube.ScalableResources() {
+			kubeRoute.PUT("/namespaces/:namespace/"+resource+"/:name/scale", k.scaleWorkload(resource))
+		}
+		for _, resource := range kube.WorkloadResources() {
+			kubeRoute.PUT("/namespaces/:namespace/"+resource+"/:name/restart", k.restartWorkload(resource))
+		}
+		kubeRoute.PUT("/namespaces/:namespace/deployments/:name/pause", k.pauseDeployment(true))
+		kubeRoute.PUT("/namespaces/:namespace/deployments/:name/resume", k.pauseDeployment(false))
+		kubeRoute.DELETE("/namespaces/:namespace/pods/:name", k.deletePod)
+		kubeRoute.POST("/namespaces/:namespace/pods/:name/eviction", k.evictPod)
+
+		// 容器日志，follow 模式下通过 websocket 或者 SSE 持续输出
+		kubeRoute.GET("/namespaces/:namespace/pods/:name/log", k.getLogs)
+		kubeRoute.GET("/namespaces/:namespace/pods/:name/log/download", k.downloadLogs)
+	}
+}
@@ -0,0 +1,591 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"k8s.io/klog/v2"
+
+	"kubevulpes/api/httputils"
+	"kubevulpes/pkg/controller/kube"
+	"kubevulpes/pkg/types"
+)
+
+func (k *kubeRouter) listObjects(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r := httputils.NewResponse()
+
+		var (
+			meta        types.VulpesObjectMeta
+			listOptions types.ListOptions
+			err         error
+		)
+		if err = httputils.ShouldBindAny(c, nil, &meta, &listOptions); err != nil {
+			httputils.SetFailed(c, r, err)
+			return
+		}
+		if r.Result, err = k.c.Kube().List(c, resource, meta, &listOptions); err != nil {
+			httputils.SetFailed(c, r, err)
+			return
+		}
+
+		httputils.SetSuccess(c, r)
+	}
+}
+
+func (k *kubeRouter) getObject(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r := httputils.NewResponse()
+
+		var (
+			meta types.VulpesObjectMeta
+			err  error
+		)
+		if err = httputils.ShouldBindAny(c, nil, &meta, nil); err != nil {
+			httputils.SetFailed(c, r, err)
+			return
+		}
+		if r.Result, err = k.c.Kube().Get(c, resource, meta); err != nil {
+			httputils.SetFailed(c, r, err)
+			return
+		}
+
+		httputils.SetSuccess(c, r)
+	}
+}
+
+func (k *kubeRouter) listClusterObjects(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r := httputils.NewResponse()
+
+		var (
+			meta        types.VulpesClusterMeta
+			listOptions types.ListOptions
+			err         error
+		)
+		if err = httputils.ShouldBindAny(c, nil, &meta, &listOptions); err != nil {
+			httputils.SetFailed(c, r, err)
+			return
+		}
+		if r.Result, err = k.c.Kube().List(c, resource, types.VulpesObjectMeta{Cluster: meta.Cluster}, &listOptions); err != nil {
+			httputils.SetFailed(c, r, err)
+			return
+		}
+
+		httputils.SetSuccess(c, r)
+	}
+}
+
+func (k *kubeRouter) getClusterObject(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r := httputils.NewResponse()
+
+		var (
+			meta types.VulpesClusterMeta
+			err  error
+		)
+		if err = httputils.ShouldBindAny(c, nil, &meta, nil); err != nil {
+			httputils.SetFailed(c, r, err)
+			return
+		}
+		if r.Result, err = k.c.Kube().Get(c, resource, types.VulpesObjectMeta{Cluster: meta.Cluster, Name: meta.Name}); err != nil {
+			httputils.SetFailed(c, r, err)
+			return
+		}
+
+		httputils.SetSuccess(c, r)
+	}
+}
+
+// getNamespace namespace 的名称位于 :namespace 参数中
+func (k *kubeRouter) getNamespace(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		meta types.VulpesObjectMeta
+		err  error
+	)
+	if err = httputils.ShouldBindAny(c, nil, &meta, nil); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = k.c.Kube().Get(c, kube.ResourceNamespaces, types.VulpesObjectMeta{Cluster: meta.Cluster, Name: meta.Namespace}); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (k *kubeRouter) listResources(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		meta        types.ResourceMeta
+		listOptions types.ListOptions
+		err         error
+	)
+	if err = httputils.ShouldBindAny(c, nil, &meta, &listOptions); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = k.c.Kube().ListResources(c, meta, &listOptions); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (k *kubeRouter) getResource(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		meta types.ResourceMeta
+		err  error
+	)
+	if err = httputils.ShouldBindAny(c, nil, &meta, nil); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = k.c.Kube().GetResource(c, meta); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (k *kubeRouter) apply(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		meta types.VulpesClusterMeta
+		req  types.ApplyRequest
+		err  error
+	)
+	if err = httputils.ShouldBindAny(c, &req, &meta, nil); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	results, err := k.c.Kube().Apply(c, meta.Cluster, &req)
+	if err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	httputils.SetAuditEvent(c, applyEvent(meta.Cluster, req.DryRun, results))
+
+	r.Result = results
+	httputils.SetSuccess(c, r)
+}
+
+// applyEvent 每个对象的应用结果记录一行
+func applyEvent(cluster string, dryRun bool, results []types.ApplyResult) string {
+	var b strings.Builder
+	mode := "apply"
+	if dryRun {
+		mode = "dry-run apply"
+	}
+	for _, result := range results {
+		name := result.Name
+		if len(result.Namespace) != 0 {
+			name = result.Namespace + "/" + name
+		}
+		fmt.Fprintf(&b, "%s %s %s %s in cluster %s: %s", mode, result.APIVersion, result.Kind, name, cluster, result.Action)
+		if len(result.Error) != 0 {
+			fmt.Fprintf(&b, " (%s)", result.Error)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (k *kubeRouter) scaleWorkload(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r := httputils.NewResponse()
+
+		var (
+			meta types.VulpesObjectMeta
+			req  types.ScaleWorkloadRequest
+			err  error
+		)
+		if err = httputils.ShouldBindAny(c, &req, &meta, nil); err != nil {
+			httputils.SetFailed(c, r, err)
+			return
+		}
+		if r.Result, err = k.c.Kube().Scale(c, resource, meta, *req.Replicas); err != nil {
+			httputils.SetFailed(c, r, err)
+			return
+		}
+
+		httputils.SetSuccess(c, r)
+	}
+}
+
+func (k *kubeRouter) restartWorkload(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r := httputils.NewResponse()
+
+		var (
+			meta types.VulpesObjectMeta
+			err  error
+		)
+		if err = httputils.ShouldBindAny(c, nil, &meta, nil); err != nil {
+			httputils.SetFailed(c, r, err)
+			return
+		}
+		if r.Result, err = k.c.Kube().Restart(c, resource, meta); err != nil {
+			httputils.SetFailed(c, r, err)
+			return
+		}
+
+		httputils.SetSuccess(c, r)
+	}
+}
+
+func (k *kubeRouter) pauseDeployment(paused bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r := httputils.NewResponse()
+
+		var (
+			meta types.VulpesObjectMeta
+			err  error
+		)
+		if err = httputils.ShouldBindAny(c, nil, &meta, nil); err != nil {
+			httputils.SetFailed(c, r, err)
+			return
+		}
+		if r.Result, err = k.c.Kube().Pause(c, meta, paused); err != nil {
+			httputils.SetFailed(c, r, err)
+			return
+		}
+
+		httputils.SetSuccess(c, r)
+	}
+}
+
+func (k *kubeRouter) deletePod(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		meta types.VulpesObjectMeta
+		req  types.DeletePodRequest
+		err  error
+	)
+	if err = httputils.ShouldBindAny(c, nil, &meta, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = k.c.Kube().DeletePod(c, meta, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (k *kubeRouter) evictPod(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		meta types.VulpesObjectMeta
+		err  error
+	)
+	if err = httputils.ShouldBindAny(c, nil, &meta, nil); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = k.c.Kube().EvictPod(c, meta); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (k *kubeRouter) getClusterUsage(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		meta types.VulpesClusterMeta
+		err  error
+	)
+	if err = httputils.ShouldBindAny(c, nil, &meta, nil); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = k.c.Kube().ClusterUsage(c, meta.Cluster); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (k *kubeRouter) getNodeUsage(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		meta types.VulpesClusterMeta
+		err  error
+	)
+	if err = httputils.ShouldBindAny(c, nil, &meta, nil); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = k.c.Kube().NodeUsage(c, meta.Cluster, meta.Name); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (k *kubeRouter) getNamespaceUsage(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		meta types.VulpesObjectMeta
+		err  error
+	)
+	if err = httputils.ShouldBindAny(c, nil, &meta, nil); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = k.c.Kube().NamespaceUsage(c, meta.Cluster, meta.Namespace); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (k *kubeRouter) listEvents(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		meta types.VulpesClusterMeta
+		opts types.EventOptions
+		err  error
+	)
+	if err = httputils.ShouldBindAny(c, nil, &meta, &opts); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = k.c.Kube().Events(c, meta.Cluster, &opts); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+// terminal 参数校验失败时仍以 http 返回错误，升级为 websocket 之后错误输出到终端中
+func (k *kubeRouter) terminal(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		opts types.WebShellOptions
+		err  error
+	)
+	// 先绑定 query 参数，uri 中的集群名称优先
+	if err = httputils.ShouldBindAny(c, nil, nil, &opts); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if err = httputils.ShouldBindAny(c, nil, &opts, nil); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	session, err := types.NewTerminalSession(c.Writer, c.Request)
+	if err != nil {
+		klog.Errorf("failed to upgrade terminal connection: %v", err)
+		return
+	}
+	defer session.Close()
+
+	if err = k.c.Kube().Terminal(c, &opts, session); err != nil {
+		_, _ = session.Write([]byte(err.Error()))
+	}
+}
+
+const (
+	// 单行日志的最大长度
+	maxLogLineSize = 1024 * 1024
+	// 非持续输出时一次返回的日志上限，客户端没有指定 tailLines 和 limitBytes 时只返回最后 defaultLogTailLines 行
+	maxLogBytes         = 10 * 1024 * 1024
+	defaultLogTailLines = 1000
+)
+
+func (k *kubeRouter) getLogs(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		meta types.VulpesObjectMeta
+		opts types.PodLogOptions
+		err  error
+	)
+	if err = httputils.ShouldBindAny(c, nil, &meta, &opts); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	if !opts.Follow {
+		if opts.TailLines == 0 && opts.LimitBytes == 0 {
+			opts.TailLines = defaultLogTailLines
+		}
+		if opts.LimitBytes == 0 || opts.LimitBytes > maxLogBytes {
+			opts.LimitBytes = maxLogBytes
+		}
+		stream, err := k.c.Kube().Logs(c, meta, &opts)
+		if err != nil {
+			httputils.SetFailed(c, r, err)
+			return
+		}
+		defer stream.Close()
+
+		data, err := io.ReadAll(io.LimitReader(stream, maxLogBytes))
+		if err != nil {
+			httputils.SetFailed(c, r, err)
+			return
+		}
+		r.Result = string(data)
+		httputils.SetSuccess(c, r)
+		return
+	}
+
+	if websocket.IsWebSocketUpgrade(c.Request) {
+		k.streamLogsByWebsocket(c, meta, &opts)
+		return
+	}
+	k.streamLogsBySSE(c, meta, &opts)
+}
+
+// streamLogsBySSE 客户端断开时 request context 被取消，上游的日志流随之关闭
+func (k *kubeRouter) streamLogsBySSE(c *gin.Context, meta types.VulpesObjectMeta, opts *types.PodLogOptions) {
+	stream, err := k.c.Kube().Logs(c.Request.Context(), meta, opts)
+	if err != nil {
+		httputils.SetFailed(c, httputils.NewResponse(), err)
+		return
+	}
+	defer stream.Close()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+
+	scanner := newLogScanner(stream)
+	c.Stream(func(w io.Writer) bool {
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil && c.Request.Context().Err() == nil {
+				c.SSEvent("error", err.Error())
+			}
+			return false
+		}
+		c.SSEvent("log", scanner.Text())
+		return true
+	})
+}
+
+// streamLogsByWebsocket 升级后 request context 不会因为客户端断开而取消，通过读取 websocket 感知断开
+func (k *kubeRouter) streamLogsByWebsocket(c *gin.Context, meta types.VulpesObjectMeta, opts *types.PodLogOptions) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := k.c.Kube().Logs(ctx, meta, opts)
+	if err != nil {
+		httputils.SetFailed(c, httputils.NewResponse(), err)
+		return
+	}
+	defer stream.Close()
+
+	conn, err := httputils.UpgradeWebsocket(c)
+	if err != nil {
+		klog.Errorf("failed to upgrade log connection: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// 客户端不会发送数据，读取失败说明连接已经断开
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	scanner := newLogScanner(stream)
+	for scanner.Scan() {
+		if err = conn.WriteMessage(websocket.TextMessage, scanner.Bytes()); err != nil {
+			return
+		}
+	}
+	if err = scanner.Err(); err != nil && ctx.Err() == nil {
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
+	}
+	_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
+
+// downloadLogs 以文件的形式下载日志，忽略 follow 参数
+func (k *kubeRouter) downloadLogs(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		meta types.VulpesObjectMeta
+		opts types.PodLogOptions
+		err  error
+	)
+	if err = httputils.ShouldBindAny(c, nil, &meta, &opts); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	opts.Follow = false
+
+	stream, err := k.c.Kube().Logs(c, meta, &opts)
+	if err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	defer stream.Close()
+
+	filename := meta.Name
+	if len(opts.Container) != 0 {
+		filename += "-" + opts.Container
+	}
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.log", filename))
+	c.Status(http.StatusOK)
+	if _, err = io.Copy(c.Writer, stream); err != nil {
+		klog.Errorf("failed to download logs of pod %s/%s in cluster(%s): %v", meta.Namespace, meta.Name, meta.Cluster, err)
+	}
+}
+
+func newLogScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLogLineSize)
+	return scanner
+}
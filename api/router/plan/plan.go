@@ -0,0 +1,49 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"github.com/gin-gonic/gin"
+
+	option "kubevulpes/cmd/app/options"
+	"kubevulpes/pkg/controller"
+)
+
+type planRouter struct {
+	c controller.VuplesInterface
+}
+
+func NewRouter(o *option.Options) {
+	r := &planRouter{c: o.Controller}
+	r.initRouter(o.HttpEngine)
+}
+
+func (pr *planRouter) initRouter(httpEngine *gin.Engine) {
+	planRoute := httpEngine.Group("/api/vulpes/plans")
+	{
+		planRoute.POST("", pr.createPlan)
+		planRoute.GET("", pr.listPlans)
+		planRoute.GET("/:planId", pr.getPlan)
+		planRoute.PUT("/:planId", pr.updatePlan)
+		planRoute.DELETE("/:planId", pr.deletePlan)
+
+		// 关联或解除关联规划部署出的集群
+		planRoute.PUT("/:planId/cluster", pr.linkCluster)
+		// 下载规划渲染出的安装包
+		planRoute.GET("/:planId/bundle", pr.downloadBundle)
+	}
+}
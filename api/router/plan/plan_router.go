@@ -0,0 +1,162 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"kubevulpes/api/httputils"
+	"kubevulpes/pkg/types"
+)
+
+type IdMeta struct {
+	PlanId int64 `uri:"planId" binding:"required"`
+}
+
+func (pr *planRouter) createPlan(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		req types.CreatePlanRequest
+		err error
+	)
+	if err = c.ShouldBindJSON(&req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = pr.c.Plan().Create(c, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (pr *planRouter) updatePlan(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		idMeta IdMeta
+		req    types.UpdatePlanRequest
+		err    error
+	)
+	if err = httputils.ShouldBindAny(c, &req, &idMeta, nil); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if err = pr.c.Plan().Update(c, idMeta.PlanId, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (pr *planRouter) deletePlan(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		idMeta IdMeta
+		err    error
+	)
+	if err = c.ShouldBindUri(&idMeta); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if err = pr.c.Plan().Delete(c, idMeta.PlanId); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (pr *planRouter) getPlan(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		idMeta IdMeta
+		err    error
+	)
+	if err = c.ShouldBindUri(&idMeta); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = pr.c.Plan().Get(c, idMeta.PlanId); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (pr *planRouter) listPlans(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		listOptions types.ListOptions
+		err         error
+	)
+	if err = httputils.ShouldBindAny(c, nil, nil, &listOptions); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = pr.c.Plan().List(c, &listOptions); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (pr *planRouter) linkCluster(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		idMeta IdMeta
+		req    types.LinkPlanClusterRequest
+		err    error
+	)
+	if err = httputils.ShouldBindAny(c, &req, &idMeta, nil); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if err = pr.c.Plan().LinkCluster(c, idMeta.PlanId, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+// downloadBundle 以 tar.gz 文件的形式下载安装包
+func (pr *planRouter) downloadBundle(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		idMeta IdMeta
+		err    error
+	)
+	if err = c.ShouldBindUri(&idMeta); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	bundle, err := pr.c.Plan().Bundle(c, idMeta.PlanId)
+	if err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", bundle.Name))
+	c.Data(http.StatusOK, "application/gzip", bundle.Data)
+}
@@ -21,6 +21,9 @@ import (
 	"kubevulpes/api/router/audit"
 	"kubevulpes/api/router/auth"
 	"kubevulpes/api/router/cluster"
+	"kubevulpes/api/router/host"
+	"kubevulpes/api/router/kube"
+	"kubevulpes/api/router/plan"
 	"kubevulpes/api/router/user"
 	option "kubevulpes/cmd/app/options"
 )
@@ -31,6 +34,9 @@ func InstallRouters(o *option.Options) {
 	fs := []RegisterFunc{
 		middleware.InstallMiddlewares,
 		cluster.NewRouter,
+		kube.NewRouter,
+		host.NewRouter,
+		plan.NewRouter,
 		user.NewRouter,
 		audit.NewRouter,
 		auth.NewRouter, // TODO: add auth router
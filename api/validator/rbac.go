@@ -0,0 +1,82 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validator
+
+import (
+	"regexp"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"kubevulpes/pkg/db/model"
+)
+
+func init() {
+	customValidators = append(customValidators,
+		&rbacValidator{tag: "rbac_object", message: "{0}必须是平台对象或者 kubernetes 资源的复数名称", fn: validateObject},
+		&rbacValidator{tag: "rbac_sid", message: "{0}必须是 * 或者合法的正则表达式", fn: validatePattern},
+		&rbacValidator{tag: "rbac_operation", message: "{0}不是支持的操作类型", fn: validateOperation},
+		&rbacValidator{tag: "rbac_namespace", message: "{0}必须是 * 或者合法的正则表达式", fn: validatePattern},
+	)
+}
+
+// rbacValidator 校验 RBAC 策略的各个字段
+type rbacValidator struct {
+	tag     string
+	message string
+	fn      func(value string) bool
+}
+
+func (r *rbacValidator) getTag() string {
+	return r.tag
+}
+
+func (r *rbacValidator) translateError(ut ut.Translator) error {
+	return ut.Add(r.tag, r.message, true)
+}
+
+func (r *rbacValidator) translate(ut ut.Translator, fe validator.FieldError) string {
+	t, _ := ut.T(r.tag, fe.Field())
+	return t
+}
+
+func (r *rbacValidator) validate(fl validator.FieldLevel) bool {
+	return r.fn(fl.Field().String())
+}
+
+// validateObject 未列出的 kubernetes 资源（例如 CRD）使用资源的复数名称
+func validateObject(value string) bool {
+	obj := model.ObjectType(value)
+	if _, ok := model.ObjectTypeMap[obj]; ok {
+		return true
+	}
+	return model.IsKubeObject(obj) && len(validation.IsDNS1123Label(value)) == 0
+}
+
+func validatePattern(value string) bool {
+	if value == "*" {
+		return true
+	}
+	_, err := regexp.Compile(value)
+	return err == nil
+}
+
+func validateOperation(value string) bool {
+	_, ok := model.OperationMap[model.Operation(value)]
+	return ok
+}
@@ -17,12 +17,18 @@ limitations under the License.
 package config
 
 import (
+	"time"
+
 	logutil "kubevulpes/pkg/util/log"
 )
 
 type Config struct {
 	DB      DBOptions      `config:"db"`
 	Default DefaultOptions `config:"default"`
+	Job     JobOptions     `config:"job"`
+
+	Encryption EncryptionOptions `config:"encryption"`
+	KubeConfig KubeConfigOptions `config:"kubeconfig"`
 }
 
 type DBOptions struct {
@@ -44,6 +50,69 @@ type DefaultOptions struct {
 	logutil.LogOptions `config:",inline"`
 }
 
+// EncryptionOptions kubeConfig 等敏感数据加密存储使用的主密钥
+// 主密钥为 base64 编码的 32 字节随机数，可通过 `head -c 32 /dev/urandom | base64` 生成
+type EncryptionOptions struct {
+	Key     string `config:"key"`      // 主密钥，优先于 key_file
+	KeyFile string `config:"key_file"` // 主密钥文件
+
+	// 密钥轮转期间仍需用于解密的历史主密钥文件
+	PreviousKeyFiles []string `config:"previous_key_files"`
+}
+
+// KubeConfigOptions 导入集群时 kubeConfig 允许使用的字段
+// 默认拒绝 exec 插件、auth-provider 和服务端本地文件引用，避免导入集群时在服务端执行命令或者读取文件
+type KubeConfigOptions struct {
+	AllowedExecCommands  []string `config:"allowed_exec_commands"`
+	AllowedAuthProviders []string `config:"allowed_auth_providers"`
+	AllowLocalFiles      bool     `config:"allow_local_files"`
+
+	// 为用户签发的 kubeConfig 中 token 的有效期，默认 1h，kubernetes 要求不少于 10m
+	UserTokenTTL time.Duration `config:"user_token_ttl"`
+}
+
+const (
+	defaultUserTokenTTL = time.Hour
+	minUserTokenTTL     = 10 * time.Minute
+)
+
+// TokenTTL 返回为用户签发的 token 有效期
+func (k *KubeConfigOptions) TokenTTL() time.Duration {
+	switch {
+	case k.UserTokenTTL <= 0:
+		return defaultUserTokenTTL
+	case k.UserTokenTTL < minUserTokenTTL:
+		return minUserTokenTTL
+	}
+	return k.UserTokenTTL
+}
+
+type JobOptions struct {
+	// 集群健康检查的 cron 表达式，默认每分钟执行一次
+	ClusterProbeSchedule string `config:"cluster_probe_schedule"`
+
+	// 回收空闲集群缓存的 cron 表达式，默认每 5 分钟执行一次
+	InformerEvictSchedule string `config:"informer_evict_schedule"`
+	// 集群缓存超过该时长未被访问时停止 informer，默认 30m
+	InformerIdleTimeout time.Duration `config:"informer_idle_timeout"`
+
+	// 清理已删除集群的 cron 表达式，默认每天 3 点执行
+	ClusterPurgeSchedule string `config:"cluster_purge_schedule"`
+	// 已删除集群的保留天数，保留期内可以恢复，默认 7 天
+	ClusterRetentionDays int `config:"cluster_retention_days"`
+}
+
+const defaultClusterRetentionDays = 7
+
+// ClusterRetention 返回已删除集群的保留时长
+func (j *JobOptions) ClusterRetention() time.Duration {
+	days := j.ClusterRetentionDays
+	if days <= 0 {
+		days = defaultClusterRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
 func (d *DefaultOptions) InDebug() bool {
 	return d.Mode == "debug"
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package option
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"time"
@@ -31,11 +32,15 @@ import (
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"k8s.io/klog/v2"
 
 	"kubevulpes/cmd/app/config"
 	"kubevulpes/pkg/controller"
+	"kubevulpes/pkg/controller/cluster"
 	"kubevulpes/pkg/db"
 	vulpesModel "kubevulpes/pkg/db/model"
+	"kubevulpes/pkg/jobmanager"
+	"kubevulpes/pkg/util/crypto"
 )
 
 const (
@@ -67,6 +72,12 @@ type Options struct {
 
 	// Authorization enforcement and policy management
 	Enforcer *casbin.SyncedEnforcer
+
+	// 敏感数据加密存储使用的密钥
+	Keyring *crypto.Keyring
+
+	// 后台定时任务
+	JobManager *jobmanager.JobManager
 }
 
 func NewOptions() (*Options, error) {
@@ -77,6 +88,47 @@ func NewOptions() (*Options, error) {
 }
 
 func (o *Options) Complete() error {
+	if err := o.loadConfig(); err != nil {
+		return err
+	}
+
+	// 注册依赖组件
+	if err := o.register(); err != nil {
+		return err
+	}
+
+	o.Controller = controller.New(o.ComponentConfig, o.Factory, o.Enforcer)
+
+	// 注册后台定时任务
+	if err := o.registerJobs(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CompleteKeyRotation 初始化密钥轮转需要的依赖
+// 新的主密钥用于加密，当前和历史主密钥仅用于解密已有数据
+func (o *Options) CompleteKeyRotation(newKeyFile string) error {
+	if err := o.loadConfig(); err != nil {
+		return err
+	}
+
+	newKey, err := crypto.LoadKey("", newKeyFile)
+	if err != nil {
+		return err
+	}
+	keys, err := o.loadKeys()
+	if err != nil {
+		return err
+	}
+	if o.Keyring, err = crypto.NewKeyring(newKey, keys...); err != nil {
+		return err
+	}
+
+	return o.registerDatabase()
+}
+
+func (o *Options) loadConfig() error {
 	// todo 获取配置文件参数
 	// 配置文件优先级: 默认配置，环境变量，命令行
 	if len(o.ConfigFile) == 0 {
@@ -99,13 +151,6 @@ func (o *Options) Complete() error {
 	if len(o.ComponentConfig.Default.JWTKey) == 0 {
 		o.ComponentConfig.Default.JWTKey = defaultTokenKey
 	}
-
-	// 注册依赖组件
-	if err := o.register(); err != nil {
-		return err
-	}
-
-	o.Controller = controller.New(o.ComponentConfig, o.Factory, o.Enforcer)
 	return nil
 }
 
@@ -127,14 +172,23 @@ func (o *Options) Binding(configFile string, conf *config.Config) error {
 
 // BindFlags binds the kubevulpes Configuration struct fields
 func (o *Options) BindFlags(cmd *cobra.Command) {
-	cmd.Flags().StringVar(&o.ConfigFile, "configfile", "", "The location of the kubevulpes configuration file")
+	cmd.PersistentFlags().StringVar(&o.ConfigFile, "configfile", "", "The location of the kubevulpes configuration file")
 }
 
 func (o *Options) register() error {
+	// 注册加密密钥
+	if err := o.registerKeyring(); err != nil {
+		return err
+	}
+
 	// 注册数据库
 	if err := o.registerDatabase(); err != nil {
 		return err
 	}
+	// 加密历史的明文数据，保证数据库中不再保存明文的敏感信息
+	if err := o.encryptLegacyData(); err != nil {
+		return err
+	}
 
 	// TODO: 注册其他依赖
 	if err := o.registerEnforcer(); err != nil {
@@ -146,6 +200,35 @@ func (o *Options) register() error {
 	return nil
 }
 
+func (o *Options) registerKeyring() error {
+	keys, err := o.loadKeys()
+	if err != nil {
+		return err
+	}
+
+	o.Keyring, err = crypto.NewKeyring(keys[0], keys[1:]...)
+	return err
+}
+
+// loadKeys 返回配置的主密钥和历史主密钥，主密钥位于第一个
+func (o *Options) loadKeys() ([][]byte, error) {
+	encOpts := o.ComponentConfig.Encryption
+	primary, err := crypto.LoadKey(encOpts.Key, encOpts.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := [][]byte{primary}
+	for _, keyFile := range encOpts.PreviousKeyFiles {
+		key, err := crypto.LoadKey("", keyFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
 func (o *Options) registerDatabase() error {
 	sqlConfig := o.ComponentConfig.DB
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8&parseTime=True&loc=Local",
@@ -175,13 +258,32 @@ func (o *Options) registerDatabase() error {
 	sqlDB.SetMaxIdleConns(maxIdleConns)
 	sqlDB.SetMaxOpenConns(maxOpenConns)
 
-	o.Factory, err = db.NewDaoFactory(dbCon, o.ComponentConfig.Default.AutoMigrate)
+	o.Factory, err = db.NewDaoFactory(dbCon, o.ComponentConfig.Default.AutoMigrate, o.Keyring)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// encryptLegacyData 启用加密之前写入的 kubeConfig 和终端会话输入仍为明文，启动时统一加密一次，已加密的数据会被跳过
+func (o *Options) encryptLegacyData() error {
+	n, err := o.Factory.Cluster().EncryptLegacy(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to encrypt legacy kubeconfigs: %v", err)
+	}
+	if n != 0 {
+		klog.Infof("%d legacy plaintext kubeconfig(s) encrypted with key %s", n, o.Keyring.PrimaryKeyID())
+	}
+
+	if n, err = o.Factory.Host().EncryptLegacySessions(context.Background()); err != nil {
+		return fmt.Errorf("failed to encrypt legacy host sessions: %v", err)
+	}
+	if n != 0 {
+		klog.Infof("%d legacy plaintext host session(s) encrypted with key %s", n, o.Keyring.PrimaryKeyID())
+	}
+	return nil
+}
+
 // This panics if o.db is nil.
 func (o *Options) registerEnforcer() error {
 	// Casbin 注册 casbin 权限表 注意 o.db 是否是 nil
@@ -193,6 +295,13 @@ func (o *Options) registerEnforcer() error {
 		return err
 	}
 
+	// 历史策略没有 ns 字段，加载前补充为不限定命名空间，否则 casbin 会因为策略长度不一致加载失败
+	if err = o.db.Table(rulesTableName).
+		Where("ptype = ? AND v4 = ?", "p", "").
+		Update("v4", vulpesModel.NamespaceAll).Error; err != nil {
+		return err
+	}
+
 	m, err := model.NewModelFromString(vulpesModel.RBACModel)
 	if err != nil {
 		return err
@@ -213,3 +322,32 @@ func (o *Options) registerEnforcer() error {
 
 	return err
 }
+
+func (o *Options) registerJobs() error {
+	proberOpts := jobmanager.DefaultProberOptions()
+	if len(o.ComponentConfig.Job.ClusterProbeSchedule) != 0 {
+		proberOpts.Schedule = o.ComponentConfig.Job.ClusterProbeSchedule
+	}
+
+	evictorOpts := jobmanager.DefaultEvictorOptions()
+	if len(o.ComponentConfig.Job.InformerEvictSchedule) != 0 {
+		evictorOpts.Schedule = o.ComponentConfig.Job.InformerEvictSchedule
+	}
+	if o.ComponentConfig.Job.InformerIdleTimeout > 0 {
+		evictorOpts.IdleTimeout = o.ComponentConfig.Job.InformerIdleTimeout
+	}
+
+	purgerOpts := jobmanager.DefaultPurgerOptions()
+	if len(o.ComponentConfig.Job.ClusterPurgeSchedule) != 0 {
+		purgerOpts.Schedule = o.ComponentConfig.Job.ClusterPurgeSchedule
+	}
+	purgerOpts.Retention = o.ComponentConfig.Job.ClusterRetention()
+
+	o.JobManager = jobmanager.NewJobManager(&o.ComponentConfig.Default.LogOptions)
+	return o.JobManager.Register(
+		jobmanager.NewAuditsCleaner(jobmanager.DefaultOptions(), o.Factory),
+		jobmanager.NewClusterProber(proberOpts, o.Factory, cluster.Indexer()),
+		jobmanager.NewInformerEvictor(evictorOpts, cluster.Indexer()),
+		jobmanager.NewClusterPurger(purgerOpts, o.Factory, o.Enforcer),
+	)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	option "kubevulpes/cmd/app/options"
+)
+
+// NewRotateKeysCommand 使用新的主密钥重新加密所有集群的 kubeConfig、主机的 SSH 认证信息和终端会话的输入
+// 执行完成后将配置中的主密钥替换为新密钥，旧密钥可加入 previous_key_files 直到所有实例完成更新
+func NewRotateKeysCommand(opts *option.Options) *cobra.Command {
+	var newKeyFile string
+
+	cmd := &cobra.Command{
+		Use:   "rotate-keys",
+		Short: "Re-encrypt stored kubeconfigs, host credentials and ssh sessions with a new key",
+		Long:  "Re-encrypt every stored cluster kubeconfig, host credential and ssh session input with the key read from --new-key-file.",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := opts.CompleteKeyRotation(newKeyFile); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			rotated, err := RotateKeys(context.Background(), opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("%d object(s) re-encrypted with key %s\n", rotated, opts.Keyring.PrimaryKeyID())
+		},
+	}
+	cmd.Flags().StringVar(&newKeyFile, "new-key-file", "", "The location of the new base64 encoded encryption key")
+	_ = cmd.MarkFlagRequired("new-key-file")
+
+	return cmd
+}
+
+// RotateKeys 读取时使用旧密钥解密，写回时使用新的主密钥加密
+// 保留期内已删除的集群仍可能被恢复，同样需要重新加密
+func RotateKeys(ctx context.Context, opt *option.Options) (int, error) {
+	objects, _, err := opt.Factory.Cluster().List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	deleted, _, err := opt.Factory.Cluster().ListDeleted(ctx)
+	if err != nil {
+		return 0, err
+	}
+	objects = append(objects, deleted...)
+
+	for _, object := range objects {
+		if err = opt.Factory.Cluster().InternalUpdate(ctx, object.Id, map[string]interface{}{
+			"kube_config": object.KubeConfig,
+		}); err != nil {
+			return 0, fmt.Errorf("failed to re-encrypt cluster(%s): %v", object.Name, err)
+		}
+		klog.Infof("cluster(%s) re-encrypted", object.Name)
+	}
+
+	hosts, _, err := opt.Factory.Host().List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, object := range hosts {
+		if err = opt.Factory.Host().InternalUpdate(ctx, object.Id, map[string]interface{}{
+			"password":    object.Password,
+			"private_key": object.PrivateKey,
+		}); err != nil {
+			return 0, fmt.Errorf("failed to re-encrypt host(%s): %v", object.Name, err)
+		}
+		klog.Infof("host(%s) re-encrypted", object.Name)
+	}
+
+	// 主机删除后会话记录仍然保留，按照会话表整体重新加密
+	sessions, err := opt.Factory.Host().ReencryptSessions(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to re-encrypt host sessions: %v", err)
+	}
+	klog.Infof("%d host session(s) re-encrypted", sessions)
+	return len(objects) + len(hosts) + sessions, nil
+}
@@ -73,6 +73,7 @@ func NewServerCommand(version string) *cobra.Command {
 		},
 	}
 	cmd.AddCommand(verCmd)
+	cmd.AddCommand(NewRotateKeysCommand(opts))
 	return cmd
 }
 
@@ -86,6 +87,17 @@ func Run(opt *option.Options) error {
 	// 安装 http 路由
 	router.InstallRouters(opt)
 
+	// 从数据库恢复集群缓存，集群在后台构建，不阻塞服务启动
+	loadCtx, loadCancel := context.WithCancel(context.Background())
+	defer loadCancel()
+	if err := opt.Controller.Cluster().Load(loadCtx); err != nil {
+		return err
+	}
+
+	// 启动后台定时任务
+	opt.JobManager.Start()
+	defer opt.JobManager.Stop()
+
 	// Initializing the server in a goroutine so that it won't block the graceful shutdown handling below
 	go func() {
 		klog.Info("starting vuples server")
@@ -95,7 +107,7 @@ func Run(opt *option.Options) error {
 	}()
 
 	// Wait for interrupt signal to gracefully shut down the server with a timeout of 5 seconds.
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	klog.Info("shutting vulpes server down ...")
@@ -17,75 +17,26 @@ limitations under the License.
 package client
 
 import (
-	"context"
 	"sync"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/informers"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
-	appsv1 "k8s.io/client-go/listers/apps/v1"
-	batchv1 "k8s.io/client-go/listers/batch/v1"
-	v1 "k8s.io/client-go/listers/core/v1"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	metricsv1beta1 "k8s.io/metrics/pkg/client/clientset/versioned/typed/metrics/v1beta1"
 )
 
-var (
-	groupVersionResources = []schema.GroupVersionResource{
-		{Group: "", Version: "v1", Resource: "pods"},
-		{Group: "", Version: "v1", Resource: "nodes"},
-		{Group: "apps", Version: "v1", Resource: "deployments"},
-		{Group: "apps", Version: "v1", Resource: "statefulsets"},
-		{Group: "apps", Version: "v1", Resource: "daemonsets"},
-		{Group: "batch", Version: "v1", Resource: "cronjobs"},
-		{Group: "batch", Version: "v1", Resource: "jobs"},
-	}
-)
-
-type VuplesInformer struct {
-	Shared informers.SharedInformerFactory
-	Cancel context.CancelFunc
-}
-
-func (p VuplesInformer) NodesLister() v1.NodeLister {
-	return p.Shared.Core().V1().Nodes().Lister()
-}
-
-func (p VuplesInformer) PodsLister() v1.PodLister {
-	return p.Shared.Core().V1().Pods().Lister()
-}
-
-func (p VuplesInformer) NamespacesLister() v1.NamespaceLister {
-	return p.Shared.Core().V1().Namespaces().Lister()
-}
-
-func (p VuplesInformer) DeploymentsLister() appsv1.DeploymentLister {
-	return p.Shared.Apps().V1().Deployments().Lister()
-}
-
-func (p *VuplesInformer) StatefulSetsLister() appsv1.StatefulSetLister {
-	return p.Shared.Apps().V1().StatefulSets().Lister()
-}
-
-func (p *VuplesInformer) DaemonSetsLister() appsv1.DaemonSetLister {
-	return p.Shared.Apps().V1().DaemonSets().Lister()
-}
-
-func (p *VuplesInformer) CronJobsLister() batchv1.CronJobLister {
-	return p.Shared.Batch().V1().CronJobs().Lister()
-}
-
-func (p *VuplesInformer) JobsLister() batchv1.JobLister { return p.Shared.Batch().V1().Jobs().Lister() }
-
 type ClusterSet struct {
 	Client   *kubernetes.Clientset
+	Dynamic  dynamic.Interface
 	Config   *restclient.Config
 	Metric   *metricsv1beta1.MetricsV1beta1Client
 	Informer *VuplesInformer
 }
 
-func (cs *ClusterSet) Complete(cfg []byte) error {
+// Complete 根据 kubeConfig 构造客户端，resources 中的资源在第一次访问时启动 informer
+func (cs *ClusterSet) Complete(cfg []byte, resources []schema.GroupVersionResource) error {
 	var err error
 	if cs.Config, err = clientcmd.RESTConfigFromKubeConfig(cfg); err != nil {
 		return err
@@ -93,41 +44,15 @@ func (cs *ClusterSet) Complete(cfg []byte) error {
 	if cs.Client, err = kubernetes.NewForConfig(cs.Config); err != nil {
 		return err
 	}
-	if cs.Metric, err = metricsv1beta1.NewForConfig(cs.Config); err != nil {
+	if cs.Dynamic, err = dynamic.NewForConfig(cs.Config); err != nil {
 		return err
 	}
-
-	sharedInformer, cancel, err := NewSharedInformers(cs.Config)
-	if err != nil {
+	if cs.Metric, err = metricsv1beta1.NewForConfig(cs.Config); err != nil {
 		return err
 	}
-	cs.Informer = &VuplesInformer{
-		Shared: sharedInformer,
-		Cancel: cancel,
-	}
-	return nil
-}
-
-func NewSharedInformers(c *restclient.Config) (informers.SharedInformerFactory, context.CancelFunc, error) {
-	// 重新构造客户端
-	clientSet, err := kubernetes.NewForConfig(c)
-	if err != nil {
-		return nil, nil, err
-	}
-	informerFactory := informers.NewSharedInformerFactory(clientSet, 0)
-	for _, gvr := range groupVersionResources {
-		if _, err = informerFactory.ForResource(gvr); err != nil {
-			return nil, nil, err
-		}
-	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	// Start all informers.
-	informerFactory.Start(ctx.Done())
-	// Wait for all caches to sync.
-	informerFactory.WaitForCacheSync(ctx.Done())
-
-	return informerFactory, cancel, nil
+	cs.Informer = NewVuplesInformer(cs.Client, cs.Dynamic, resources)
+	return nil
 }
 
 type store map[string]ClusterSet
@@ -184,26 +109,46 @@ func (s *Cache) Set(name string, cs ClusterSet) {
 	s.store[name] = cs
 }
 
+// Replace 原子替换集群的 clusterSet，并永久停止旧 clusterSet 的 informer
+func (s *Cache) Replace(name string, cs ClusterSet) {
+	s.Lock()
+	if s.store == nil {
+		s.store = store{}
+	}
+	old, ok := s.store[name]
+	s.store[name] = cs
+	s.Unlock()
+
+	if ok && old.Informer != nil {
+		old.Informer.Stop()
+	}
+}
+
 func (s *Cache) Delete(name string) {
 	s.Lock()
 	defer s.Unlock()
 
-	// Cancel informer
+	// Stop informer
 	cluster, ok := s.store[name]
 	if !ok {
 		return
 	}
-	cluster.Informer.Cancel()
+	cluster.Informer.Stop()
 
 	// 从缓存移除集群数据
 	delete(s.store, name)
 }
 
+// List 返回缓存的副本，调用方可以在不持有锁的情况下遍历
 func (s *Cache) List() store {
-	s.Lock()
-	defer s.Unlock()
+	s.RLock()
+	defer s.RUnlock()
 
-	return s.store
+	items := make(store, len(s.store))
+	for name, cs := range s.store {
+		items[name] = cs
+	}
+	return items
 }
 
 func (s *Cache) Clear() {
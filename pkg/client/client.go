@@ -19,6 +19,7 @@ package client
 import (
 	"encoding/base64"
 
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 )
@@ -50,14 +51,15 @@ func NewClientSetFromString(cfg string) (*kubernetes.Clientset, error) {
 	return NewClientSetFromBytes(kubeConfigBytes)
 }
 
-func NewClusterSet(cfg string) (*ClusterSet, error) {
+// NewClusterSet 构造集群的 clusterSet，resources 为空时缓存默认资源
+func NewClusterSet(cfg string, resources []schema.GroupVersionResource) (*ClusterSet, error) {
 	kubeConfigBytes, err := ParseKubeConfigBytes(cfg)
 	if err != nil {
 		return nil, err
 	}
 
 	cs := &ClusterSet{}
-	if err = cs.Complete(kubeConfigBytes); err != nil {
+	if err = cs.Complete(kubeConfigBytes, resources); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,128 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	credentialClusterName = "kubernetes"
+	credentialUserName    = "kubevulpes"
+)
+
+// Credential 集群的连接凭证，token 和客户端证书二选一
+type Credential struct {
+	Server                string
+	CertificateAuthority  []byte
+	InsecureSkipTLSVerify bool
+
+	Token             string
+	ClientCertificate []byte
+	ClientKey         []byte
+}
+
+// Validate 检查凭证是否完整，以及证书和私钥是否合法
+func (c *Credential) Validate() error {
+	if len(c.Server) == 0 {
+		return fmt.Errorf("server is required")
+	}
+	if len(c.CertificateAuthority) != 0 {
+		if !x509.NewCertPool().AppendCertsFromPEM(c.CertificateAuthority) {
+			return fmt.Errorf("invalid certificate authority")
+		}
+	}
+
+	hasToken := len(c.Token) != 0
+	hasCert := len(c.ClientCertificate) != 0 || len(c.ClientKey) != 0
+	switch {
+	case hasToken && hasCert:
+		return fmt.Errorf("token and client certificate are mutually exclusive")
+	case hasToken:
+		return nil
+	case hasCert:
+		if _, err := tls.X509KeyPair(c.ClientCertificate, c.ClientKey); err != nil {
+			return fmt.Errorf("invalid client certificate: %v", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("token or client certificate is required")
+	}
+}
+
+// KubeConfig 将凭证转换为 base64 编码的 kubeConfig，与导入的 kubeConfig 使用相同的存储格式
+func (c *Credential) KubeConfig() (string, error) {
+	data, err := c.Write(credentialClusterName, credentialUserName)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Write 将凭证转换为只包含一个 context 的 kubeConfig 文件内容
+func (c *Credential) Write(clusterName, userName string) ([]byte, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	contextName := userName + "@" + clusterName
+	config := clientcmdapi.NewConfig()
+	config.Clusters[clusterName] = &clientcmdapi.Cluster{
+		Server:                   c.Server,
+		CertificateAuthorityData: c.CertificateAuthority,
+		InsecureSkipTLSVerify:    c.InsecureSkipTLSVerify,
+	}
+	config.AuthInfos[userName] = &clientcmdapi.AuthInfo{
+		Token:                 c.Token,
+		ClientCertificateData: c.ClientCertificate,
+		ClientKeyData:         c.ClientKey,
+	}
+	config.Contexts[contextName] = &clientcmdapi.Context{
+		Cluster:  clusterName,
+		AuthInfo: userName,
+	}
+	config.CurrentContext = contextName
+
+	return clientcmd.Write(*config)
+}
+
+// DecodePEM 解析 PEM 格式的证书或者私钥，支持原始文本和 base64 编码
+func DecodePEM(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if len(s) == 0 {
+		return nil, nil
+	}
+	if strings.HasPrefix(s, "-----BEGIN") {
+		return []byte(s + "\n"), nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("PEM data must be plain text or base64 encoded")
+	}
+	if !strings.HasPrefix(strings.TrimSpace(string(data)), "-----BEGIN") {
+		return nil, fmt.Errorf("invalid PEM data")
+	}
+	return data, nil
+}
@@ -0,0 +1,515 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	appsv1 "k8s.io/client-go/listers/apps/v1"
+	batchv1 "k8s.io/client-go/listers/batch/v1"
+	v1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	PodsResource         = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	NodesResource        = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "nodes"}
+	NamespacesResource   = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "namespaces"}
+	EventsResource       = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "events"}
+	DeploymentsResource  = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	StatefulSetsResource = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"}
+	DaemonSetsResource   = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"}
+	CronJobsResource     = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "cronjobs"}
+	JobsResource         = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
+
+	// 集群未配置缓存资源时使用的默认列表
+	defaultResources = []schema.GroupVersionResource{
+		PodsResource,
+		NodesResource,
+		NamespacesResource,
+		EventsResource,
+		DeploymentsResource,
+		StatefulSetsResource,
+		DaemonSetsResource,
+		CronJobsResource,
+		JobsResource,
+	}
+
+	// 集群状态探测和资源统计依赖的资源，无论如何配置都会缓存
+	requiredResources = []schema.GroupVersionResource{
+		NodesResource,
+		NamespacesResource,
+	}
+)
+
+// DefaultResources 返回默认缓存的资源列表
+func DefaultResources() []schema.GroupVersionResource {
+	return append([]schema.GroupVersionResource{}, defaultResources...)
+}
+
+// IsRequiredResource 判断资源是否必须缓存
+func IsRequiredResource(gvr schema.GroupVersionResource) bool {
+	for _, required := range requiredResources {
+		if gvr == required {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseResource 解析 group/version/resource 格式的资源，核心组的资源使用 version/resource
+func ParseResource(s string) (schema.GroupVersionResource, error) {
+	parts := strings.Split(strings.TrimSpace(s), "/")
+	for _, part := range parts {
+		if len(part) == 0 {
+			return schema.GroupVersionResource{}, fmt.Errorf("invalid resource %q", s)
+		}
+	}
+
+	switch len(parts) {
+	case 2:
+		return schema.GroupVersionResource{Version: parts[0], Resource: parts[1]}, nil
+	case 3:
+		return schema.GroupVersionResource{Group: parts[0], Version: parts[1], Resource: parts[2]}, nil
+	default:
+		return schema.GroupVersionResource{}, fmt.Errorf("invalid resource %q, expected group/version/resource", s)
+	}
+}
+
+// ParseResources 解析资源列表，为空时返回默认列表
+func ParseResources(items []string) ([]schema.GroupVersionResource, error) {
+	if len(items) == 0 {
+		return DefaultResources(), nil
+	}
+
+	gvrs := make([]schema.GroupVersionResource, 0, len(items))
+	for _, item := range items {
+		gvr, err := ParseResource(item)
+		if err != nil {
+			return nil, err
+		}
+		gvrs = append(gvrs, gvr)
+	}
+	return gvrs, nil
+}
+
+// FormatResource 是 ParseResource 的逆操作
+func FormatResource(gvr schema.GroupVersionResource) string {
+	if len(gvr.Group) == 0 {
+		return gvr.Version + "/" + gvr.Resource
+	}
+	return gvr.Group + "/" + gvr.Version + "/" + gvr.Resource
+}
+
+// FormatResources 格式化资源列表，并补齐必须缓存的资源
+func FormatResources(gvrs []schema.GroupVersionResource) []string {
+	items := make([]string, 0, len(gvrs))
+	for _, gvr := range withRequired(gvrs) {
+		items = append(items, FormatResource(gvr))
+	}
+	return items
+}
+
+// ValidateResources 通过 discovery 检查资源是否存在并且支持 list 和 watch
+// 不存在的资源会导致 informer 无法同步
+func ValidateResources(client discovery.DiscoveryInterface, gvrs []schema.GroupVersionResource) error {
+	served := make(map[schema.GroupVersion][]metav1.APIResource)
+	for _, gvr := range gvrs {
+		gv := gvr.GroupVersion()
+		resources, ok := served[gv]
+		if !ok {
+			list, err := client.ServerResourcesForGroupVersion(gv.String())
+			if err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+			if list != nil {
+				resources = list.APIResources
+			}
+			served[gv] = resources
+		}
+
+		if !hasListWatch(resources, gvr.Resource) {
+			return fmt.Errorf("resource %s is not served or does not support list and watch", FormatResource(gvr))
+		}
+	}
+	return nil
+}
+
+func hasListWatch(resources []metav1.APIResource, name string) bool {
+	for _, resource := range resources {
+		if resource.Name != name {
+			continue
+		}
+		verbs := sets.NewString(resource.Verbs...)
+		return verbs.HasAll("list", "watch")
+	}
+	return false
+}
+
+// withRequired 去重并补齐必须缓存的资源，保持原有顺序
+func withRequired(gvrs []schema.GroupVersionResource) []schema.GroupVersionResource {
+	seen := make(map[schema.GroupVersionResource]bool)
+	result := make([]schema.GroupVersionResource, 0, len(gvrs)+len(requiredResources))
+	for _, gvr := range append(append([]schema.GroupVersionResource{}, requiredResources...), gvrs...) {
+		if seen[gvr] {
+			continue
+		}
+		seen[gvr] = true
+		result = append(result, gvr)
+	}
+	return result
+}
+
+// ResourceStatus 单个资源 informer 的运行和同步状态
+type ResourceStatus struct {
+	Resource schema.GroupVersionResource
+	Started  bool
+	Synced   bool
+}
+
+type resourceInformer struct {
+	informers.GenericInformer
+	cancel context.CancelFunc
+}
+
+// VuplesInformer 按资源管理集群的 informer，每个资源独立启停
+// 内置资源使用 typed informer，其余资源（如 CRD）使用 dynamic informer
+// informer 在第一次访问时才启动，Cancel 停止全部 informer 释放缓存，再次访问时重新启动
+type VuplesInformer struct {
+	client  kubernetes.Interface
+	dynamic dynamic.Interface
+
+	ctx  context.Context
+	stop context.CancelFunc
+
+	lock sync.RWMutex
+	// 配置缓存的资源，未启动的资源值为 nil
+	informers map[schema.GroupVersionResource]*resourceInformer
+
+	// 最近一次通过 Sync 访问的时间，用于回收长时间未访问的缓存
+	lastAccess atomic.Int64
+}
+
+// NewVuplesInformer 记录需要缓存的资源，不会立即启动 informer
+func NewVuplesInformer(client kubernetes.Interface, dynamicClient dynamic.Interface, resources []schema.GroupVersionResource) *VuplesInformer {
+	ctx, stop := context.WithCancel(context.Background())
+	p := &VuplesInformer{
+		client:    client,
+		dynamic:   dynamicClient,
+		ctx:       ctx,
+		stop:      stop,
+		informers: make(map[schema.GroupVersionResource]*resourceInformer),
+	}
+	p.lastAccess.Store(time.Now().UnixNano())
+	p.SetResources(resources)
+	return p
+}
+
+// SetResources 在运行时调整缓存的资源，移除的资源停止同步并释放缓存，新增的资源在访问时启动
+func (p *VuplesInformer) SetResources(resources []schema.GroupVersionResource) {
+	if len(resources) == 0 {
+		resources = defaultResources
+	}
+
+	desired := make(map[schema.GroupVersionResource]bool)
+	for _, gvr := range withRequired(resources) {
+		desired[gvr] = true
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for gvr, ri := range p.informers {
+		if !desired[gvr] {
+			if ri != nil {
+				ri.cancel()
+			}
+			delete(p.informers, gvr)
+		}
+	}
+	for gvr := range desired {
+		if _, ok := p.informers[gvr]; !ok {
+			p.informers[gvr] = nil
+		}
+	}
+}
+
+// informer 返回资源的 informer，未启动时立即启动
+// 资源未配置缓存时返回 errNotCached，informer 已被永久停止时返回错误，避免在已取消的 ctx 上启动永远无法同步的 informer
+func (p *VuplesInformer) informer(gvr schema.GroupVersionResource) (informers.GenericInformer, error) {
+	if p.ctx.Err() != nil {
+		return nil, errStopped
+	}
+
+	p.lock.RLock()
+	ri, ok := p.informers[gvr]
+	p.lock.RUnlock()
+	if !ok {
+		return nil, errNotCached(gvr)
+	}
+	if ri != nil {
+		return ri, nil
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// 加锁期间资源可能已被移除、被其他请求启动或者 informer 已被停止
+	if p.ctx.Err() != nil {
+		return nil, errStopped
+	}
+	ri, ok = p.informers[gvr]
+	if !ok {
+		return nil, errNotCached(gvr)
+	}
+	if ri == nil {
+		ri = p.newResourceInformer(gvr)
+		p.informers[gvr] = ri
+	}
+	return ri, nil
+}
+
+var errStopped = fmt.Errorf("informer has been stopped")
+
+func errNotCached(gvr schema.GroupVersionResource) error {
+	return fmt.Errorf("resource %s is not cached", FormatResource(gvr))
+}
+
+func (p *VuplesInformer) newResourceInformer(gvr schema.GroupVersionResource) *resourceInformer {
+	// 每个资源使用独立的 factory，以便单独停止
+	generic, err := informers.NewSharedInformerFactory(p.client, 0).ForResource(gvr)
+	if err != nil {
+		generic = dynamicinformer.NewFilteredDynamicInformer(p.dynamic, gvr, metav1.NamespaceAll, 0, namespaceIndexers(), nil)
+	}
+
+	ctx, cancel := context.WithCancel(p.ctx)
+	go generic.Informer().Run(ctx.Done())
+	return &resourceInformer{GenericInformer: generic, cancel: cancel}
+}
+
+// Sync 启动资源的 informer 并等待缓存同步完成，同时记录访问时间
+// 面向用户的查询在读取 lister 前调用，ctx 结束时返回错误
+func (p *VuplesInformer) Sync(ctx context.Context, gvrs ...schema.GroupVersionResource) error {
+	p.lastAccess.Store(time.Now().UnixNano())
+
+	for _, gvr := range gvrs {
+		informer, err := p.informer(gvr)
+		if err != nil {
+			return err
+		}
+		if !cache.WaitForCacheSync(ctx.Done(), informer.Informer().HasSynced) {
+			return fmt.Errorf("failed to sync informer cache for %s", FormatResource(gvr))
+		}
+	}
+	return nil
+}
+
+// Resources 返回配置缓存的资源及其运行和同步状态
+func (p *VuplesInformer) Resources() []ResourceStatus {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	statuses := make([]ResourceStatus, 0, len(p.informers))
+	for gvr, ri := range p.informers {
+		status := ResourceStatus{Resource: gvr}
+		if ri != nil {
+			status.Started = true
+			status.Synced = ri.Informer().HasSynced()
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return FormatResource(statuses[i].Resource) < FormatResource(statuses[j].Resource)
+	})
+	return statuses
+}
+
+// HasResource 判断资源是否配置了缓存
+func (p *VuplesInformer) HasResource(gvr schema.GroupVersionResource) bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	_, ok := p.informers[gvr]
+	return ok
+}
+
+// HasSynced 判断资源的 informer 是否已经启动并且完成同步
+func (p *VuplesInformer) HasSynced(gvr schema.GroupVersionResource) bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	ri := p.informers[gvr]
+	return ri != nil && ri.Informer().HasSynced()
+}
+
+// HasStarted 判断资源的 informer 是否已经启动
+func (p *VuplesInformer) HasStarted(gvr schema.GroupVersionResource) bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.informers[gvr] != nil
+}
+
+// Running 判断是否有已经启动的 informer
+func (p *VuplesInformer) Running() bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	for _, ri := range p.informers {
+		if ri != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// LastAccess 返回最近一次访问缓存的时间
+func (p *VuplesInformer) LastAccess() time.Time {
+	return time.Unix(0, p.lastAccess.Load())
+}
+
+// Cancel 停止全部 informer 并释放缓存，资源配置保持不变，再次访问时重新启动
+func (p *VuplesInformer) Cancel() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for gvr, ri := range p.informers {
+		if ri != nil {
+			ri.cancel()
+			p.informers[gvr] = nil
+		}
+	}
+}
+
+// Stop 永久停止全部 informer，用于集群删除或者 clusterSet 被替换
+func (p *VuplesInformer) Stop() {
+	p.stop()
+	p.Cancel()
+}
+
+// Lister 返回任意已缓存资源的通用 lister
+func (p *VuplesInformer) Lister(gvr schema.GroupVersionResource) (cache.GenericLister, error) {
+	informer, err := p.informer(gvr)
+	if err != nil {
+		return nil, err
+	}
+	return informer.Lister(), nil
+}
+
+// indexer 返回资源的缓存索引
+// 资源未配置缓存、informer 已停止或者未通过 Sync 等待同步时（例如 Cancel 之后重新启动）返回错误，而不是空的结果
+func (p *VuplesInformer) indexer(gvr schema.GroupVersionResource) (cache.Indexer, error) {
+	informer, err := p.informer(gvr)
+	if err != nil {
+		return nil, err
+	}
+	if !informer.Informer().HasSynced() {
+		return nil, fmt.Errorf("informer cache for %s is not synced", FormatResource(gvr))
+	}
+	return informer.Informer().GetIndexer(), nil
+}
+
+func namespaceIndexers() cache.Indexers {
+	return cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}
+}
+
+func (p *VuplesInformer) NodesLister() (v1.NodeLister, error) {
+	indexer, err := p.indexer(NodesResource)
+	if err != nil {
+		return nil, err
+	}
+	return v1.NewNodeLister(indexer), nil
+}
+
+func (p *VuplesInformer) PodsLister() (v1.PodLister, error) {
+	indexer, err := p.indexer(PodsResource)
+	if err != nil {
+		return nil, err
+	}
+	return v1.NewPodLister(indexer), nil
+}
+
+func (p *VuplesInformer) NamespacesLister() (v1.NamespaceLister, error) {
+	indexer, err := p.indexer(NamespacesResource)
+	if err != nil {
+		return nil, err
+	}
+	return v1.NewNamespaceLister(indexer), nil
+}
+
+func (p *VuplesInformer) EventsLister() (v1.EventLister, error) {
+	indexer, err := p.indexer(EventsResource)
+	if err != nil {
+		return nil, err
+	}
+	return v1.NewEventLister(indexer), nil
+}
+
+func (p *VuplesInformer) DeploymentsLister() (appsv1.DeploymentLister, error) {
+	indexer, err := p.indexer(DeploymentsResource)
+	if err != nil {
+		return nil, err
+	}
+	return appsv1.NewDeploymentLister(indexer), nil
+}
+
+func (p *VuplesInformer) StatefulSetsLister() (appsv1.StatefulSetLister, error) {
+	indexer, err := p.indexer(StatefulSetsResource)
+	if err != nil {
+		return nil, err
+	}
+	return appsv1.NewStatefulSetLister(indexer), nil
+}
+
+func (p *VuplesInformer) DaemonSetsLister() (appsv1.DaemonSetLister, error) {
+	indexer, err := p.indexer(DaemonSetsResource)
+	if err != nil {
+		return nil, err
+	}
+	return appsv1.NewDaemonSetLister(indexer), nil
+}
+
+func (p *VuplesInformer) CronJobsLister() (batchv1.CronJobLister, error) {
+	indexer, err := p.indexer(CronJobsResource)
+	if err != nil {
+		return nil, err
+	}
+	return batchv1.NewCronJobLister(indexer), nil
+}
+
+func (p *VuplesInformer) JobsLister() (batchv1.JobLister, error) {
+	indexer, err := p.indexer(JobsResource)
+	if err != nil {
+		return nil, err
+	}
+	return batchv1.NewJobLister(indexer), nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPodsLister(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+
+	cases := []struct {
+		name      string
+		resources []schema.GroupVersionResource
+		prepare   func(t *testing.T, p *VuplesInformer)
+		expectErr bool
+	}{
+		{
+			name:      "synced",
+			resources: []schema.GroupVersionResource{PodsResource},
+			prepare: func(t *testing.T, p *VuplesInformer) {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				if err := p.Sync(ctx, PodsResource); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name:      "not cached",
+			resources: []schema.GroupVersionResource{NodesResource},
+			prepare:   func(*testing.T, *VuplesInformer) {},
+			expectErr: true,
+		},
+		{
+			name:      "not synced",
+			resources: []schema.GroupVersionResource{PodsResource},
+			prepare:   func(*testing.T, *VuplesInformer) {},
+			expectErr: true,
+		},
+		{
+			name:      "stopped",
+			resources: []schema.GroupVersionResource{PodsResource},
+			prepare:   func(_ *testing.T, p *VuplesInformer) { p.Stop() },
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewVuplesInformer(fake.NewSimpleClientset(pod), nil, tc.resources)
+			defer p.Stop()
+			tc.prepare(t, p)
+
+			lister, err := p.PodsLister()
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected an error instead of an empty lister")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			pods, err := lister.Pods("default").List(labels.Everything())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pods) != 1 || pods[0].Name != "foo" {
+				t.Fatalf("unexpected pods %v", pods)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// KubeConfigPolicy 用户提交的 kubeConfig 允许使用的字段
+// exec 插件和 auth-provider 会让服务端执行命令或者访问外部服务，本地文件引用会读取服务端的文件，默认全部拒绝
+type KubeConfigPolicy struct {
+	AllowedExecCommands  []string // 允许的 exec 插件命令，需要与 kubeConfig 中的 command 完全一致
+	AllowedAuthProviders []string // 允许的 auth-provider 名称
+	AllowLocalFiles      bool     // 是否允许引用服务端本地的证书、私钥和 token 文件
+}
+
+// SanitizeKubeConfig 检查 base64 编码的 kubeConfig，只保留 current-context 引用的集群和用户
+// 返回裁剪后的 base64 编码的 kubeConfig，包含不允许的字段时返回错误
+func SanitizeKubeConfig(cfg string, policy KubeConfigPolicy) (string, error) {
+	data, err := ParseKubeConfigBytes(cfg)
+	if err != nil {
+		return "", fmt.Errorf("kubeConfig 不是合法的 base64 编码: %v", err)
+	}
+	config, err := clientcmd.Load(data)
+	if err != nil {
+		return "", fmt.Errorf("kubeConfig 解析失败: %v", err)
+	}
+
+	sanitized, err := SanitizeContext(config, config.CurrentContext, policy)
+	if err != nil {
+		return "", err
+	}
+	if data, err = clientcmd.Write(*sanitized); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// SanitizeContext 返回只包含指定 context 的 kubeConfig，context 为空且只有一个 context 时使用该 context
+func SanitizeContext(config *clientcmdapi.Config, contextName string, policy KubeConfigPolicy) (*clientcmdapi.Config, error) {
+	if len(contextName) == 0 && len(config.Contexts) == 1 {
+		for name := range config.Contexts {
+			contextName = name
+		}
+	}
+	if len(contextName) == 0 {
+		return nil, fmt.Errorf("kubeConfig 未指定 current-context")
+	}
+	context, ok := config.Contexts[contextName]
+	if !ok {
+		return nil, fmt.Errorf("kubeConfig 中不存在 context %q", contextName)
+	}
+	cluster, ok := config.Clusters[context.Cluster]
+	if !ok {
+		return nil, fmt.Errorf("kubeConfig 中不存在 cluster %q", context.Cluster)
+	}
+	authInfo, ok := config.AuthInfos[context.AuthInfo]
+	if !ok {
+		return nil, fmt.Errorf("kubeConfig 中不存在 user %q", context.AuthInfo)
+	}
+
+	if violations := policy.check(cluster, authInfo); len(violations) != 0 {
+		return nil, fmt.Errorf("kubeConfig 包含不允许的配置: %s", strings.Join(violations, "; "))
+	}
+
+	// 命名空间等 context 之外的字段不影响连接，裁剪掉未使用的集群、用户和扩展字段
+	sanitized := clientcmdapi.NewConfig()
+	sanitized.Clusters[context.Cluster] = cluster.DeepCopy()
+	sanitized.AuthInfos[context.AuthInfo] = authInfo.DeepCopy()
+	sanitized.Contexts[contextName] = &clientcmdapi.Context{
+		Cluster:   context.Cluster,
+		AuthInfo:  context.AuthInfo,
+		Namespace: context.Namespace,
+	}
+	sanitized.CurrentContext = contextName
+	return sanitized, nil
+}
+
+func (p KubeConfigPolicy) check(cluster *clientcmdapi.Cluster, authInfo *clientcmdapi.AuthInfo) []string {
+	var violations []string
+	if !p.AllowLocalFiles {
+		for _, file := range []struct{ field, value string }{
+			{"certificate-authority", cluster.CertificateAuthority},
+			{"client-certificate", authInfo.ClientCertificate},
+			{"client-key", authInfo.ClientKey},
+			{"tokenFile", authInfo.TokenFile},
+		} {
+			if len(file.value) != 0 {
+				violations = append(violations, fmt.Sprintf("不允许引用本地文件 %s", file.field))
+			}
+		}
+	}
+	if authInfo.Exec != nil && !contains(p.AllowedExecCommands, authInfo.Exec.Command) {
+		violations = append(violations, fmt.Sprintf("不允许使用 exec 插件 %q", authInfo.Exec.Command))
+	}
+	if authInfo.AuthProvider != nil && !contains(p.AllowedAuthProviders, authInfo.AuthProvider.Name) {
+		violations = append(violations, fmt.Sprintf("不允许使用 auth-provider %q", authInfo.AuthProvider.Name))
+	}
+	return violations
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestKubeConfigPolicyCheck(t *testing.T) {
+	cases := []struct {
+		name       string
+		policy     KubeConfigPolicy
+		cluster    clientcmdapi.Cluster
+		authInfo   clientcmdapi.AuthInfo
+		violations []string
+	}{
+		{
+			name:     "inline credentials",
+			cluster:  clientcmdapi.Cluster{Server: "https://127.0.0.1:6443", CertificateAuthorityData: []byte("ca")},
+			authInfo: clientcmdapi.AuthInfo{ClientCertificateData: []byte("cert"), ClientKeyData: []byte("key"), Token: "token"},
+		},
+		{
+			name:     "local files",
+			cluster:  clientcmdapi.Cluster{CertificateAuthority: "/etc/kubernetes/pki/ca.crt"},
+			authInfo: clientcmdapi.AuthInfo{ClientCertificate: "/root/client.crt", ClientKey: "/root/client.key", TokenFile: "/var/run/token"},
+			violations: []string{
+				"不允许引用本地文件 certificate-authority",
+				"不允许引用本地文件 client-certificate",
+				"不允许引用本地文件 client-key",
+				"不允许引用本地文件 tokenFile",
+			},
+		},
+		{
+			name:     "local files allowed",
+			policy:   KubeConfigPolicy{AllowLocalFiles: true},
+			cluster:  clientcmdapi.Cluster{CertificateAuthority: "/etc/kubernetes/pki/ca.crt"},
+			authInfo: clientcmdapi.AuthInfo{TokenFile: "/var/run/token"},
+		},
+		{
+			name:       "exec plugin",
+			authInfo:   clientcmdapi.AuthInfo{Exec: &clientcmdapi.ExecConfig{Command: "/bin/sh"}},
+			violations: []string{`不允许使用 exec 插件 "/bin/sh"`},
+		},
+		{
+			name:     "allowed exec plugin",
+			policy:   KubeConfigPolicy{AllowedExecCommands: []string{"aws-iam-authenticator"}},
+			authInfo: clientcmdapi.AuthInfo{Exec: &clientcmdapi.ExecConfig{Command: "aws-iam-authenticator"}},
+		},
+		{
+			name:       "exec command must match exactly",
+			policy:     KubeConfigPolicy{AllowedExecCommands: []string{"aws-iam-authenticator"}},
+			authInfo:   clientcmdapi.AuthInfo{Exec: &clientcmdapi.ExecConfig{Command: "/tmp/aws-iam-authenticator"}},
+			violations: []string{`不允许使用 exec 插件 "/tmp/aws-iam-authenticator"`},
+		},
+		{
+			name:       "auth provider",
+			authInfo:   clientcmdapi.AuthInfo{AuthProvider: &clientcmdapi.AuthProviderConfig{Name: "gcp"}},
+			violations: []string{`不允许使用 auth-provider "gcp"`},
+		},
+		{
+			name:     "allowed auth provider",
+			policy:   KubeConfigPolicy{AllowedAuthProviders: []string{"oidc"}},
+			authInfo: clientcmdapi.AuthInfo{AuthProvider: &clientcmdapi.AuthProviderConfig{Name: "oidc"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			violations := tc.policy.check(&tc.cluster, &tc.authInfo)
+			if !reflect.DeepEqual(violations, tc.violations) {
+				t.Fatalf("expected violations %q, got %q", tc.violations, violations)
+			}
+		})
+	}
+}
+
+func encodeKubeConfig(t *testing.T, config *clientcmdapi.Config) string {
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func TestSanitizeKubeConfig(t *testing.T) {
+	config := clientcmdapi.NewConfig()
+	config.Clusters["prod"] = &clientcmdapi.Cluster{Server: "https://prod:6443", CertificateAuthorityData: []byte("ca")}
+	config.Clusters["dev"] = &clientcmdapi.Cluster{Server: "https://dev:6443"}
+	config.AuthInfos["admin"] = &clientcmdapi.AuthInfo{Token: "token"}
+	config.AuthInfos["exec"] = &clientcmdapi.AuthInfo{Exec: &clientcmdapi.ExecConfig{Command: "/bin/sh"}}
+	config.Contexts["prod"] = &clientcmdapi.Context{Cluster: "prod", AuthInfo: "admin", Namespace: "default"}
+	config.Contexts["dev"] = &clientcmdapi.Context{Cluster: "dev", AuthInfo: "exec"}
+
+	cases := []struct {
+		name           string
+		currentContext string
+		err            string
+	}{
+		{name: "keeps only the current context", currentContext: "prod"},
+		{name: "rejects exec plugin of the current context", currentContext: "dev", err: "exec 插件"},
+		{name: "requires current context", err: "current-context"},
+		{name: "unknown context", currentContext: "missing", err: "不存在 context"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := config.DeepCopy()
+			c.CurrentContext = tc.currentContext
+
+			sanitized, err := SanitizeKubeConfig(encodeKubeConfig(t, c), KubeConfigPolicy{})
+			if len(tc.err) != 0 {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error containing %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			data, err := base64.StdEncoding.DecodeString(sanitized)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := clientcmd.Load(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Clusters) != 1 || len(result.AuthInfos) != 1 || len(result.Contexts) != 1 {
+				t.Fatalf("expected only the current context to be kept, got %d clusters, %d users and %d contexts",
+					len(result.Clusters), len(result.AuthInfos), len(result.Contexts))
+			}
+			if result.CurrentContext != "prod" || result.Clusters["prod"].Server != "https://prod:6443" || result.AuthInfos["admin"].Token != "token" {
+				t.Fatalf("unexpected sanitized kubeConfig %+v", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+// ResourceAmount cpu 的单位为 millicore，memory 的单位为 byte
+type ResourceAmount struct {
+	Cpu    int64
+	Memory int64
+}
+
+func (a *ResourceAmount) add(list v1.ResourceList) {
+	a.Cpu += list.Cpu().MilliValue()
+	a.Memory += list.Memory().Value()
+}
+
+// ResourceSummary 资源的使用量、申请量、限制量和可分配总量
+// 使用量来自 metrics-server，集群未安装 metrics-server 时 MetricsAvailable 为 false
+type ResourceSummary struct {
+	Used      ResourceAmount
+	Requested ResourceAmount
+	Limits    ResourceAmount
+	Capacity  ResourceAmount
+
+	MetricsAvailable bool
+}
+
+func (cs *ClusterSet) listNodes() ([]*v1.Node, error) {
+	lister, err := cs.Informer.NodesLister()
+	if err != nil {
+		return nil, err
+	}
+	return lister.List(labels.Everything())
+}
+
+func (cs *ClusterSet) listPods(namespace string) ([]*v1.Pod, error) {
+	lister, err := cs.Informer.PodsLister()
+	if err != nil {
+		return nil, err
+	}
+	return lister.Pods(namespace).List(labels.Everything())
+}
+
+// ClusterResources 统计整个集群的资源
+func (cs *ClusterSet) ClusterResources(ctx context.Context) (*ResourceSummary, error) {
+	nodes, err := cs.listNodes()
+	if err != nil {
+		return nil, err
+	}
+	pods, err := cs.listPods(metav1.NamespaceAll)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &ResourceSummary{}
+	for _, node := range nodes {
+		summary.Capacity.add(node.Status.Allocatable)
+	}
+	summary.addPods(pods)
+
+	nodeMetrics, err := cs.Metric.NodeMetricses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Warningf("failed to list node metrics: %v", err)
+		return summary, nil
+	}
+	for _, metric := range nodeMetrics.Items {
+		summary.Used.add(metric.Usage)
+	}
+	summary.MetricsAvailable = true
+	return summary, nil
+}
+
+// NodeResources 统计单个节点的资源，申请量为调度到该节点上的 pod 之和
+func (cs *ClusterSet) NodeResources(ctx context.Context, name string) (*ResourceSummary, error) {
+	nodeLister, err := cs.Informer.NodesLister()
+	if err != nil {
+		return nil, err
+	}
+	node, err := nodeLister.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := cs.listPods(metav1.NamespaceAll)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &ResourceSummary{}
+	summary.Capacity.add(node.Status.Allocatable)
+	nodePods := make([]*v1.Pod, 0)
+	for _, pod := range pods {
+		if pod.Spec.NodeName == name {
+			nodePods = append(nodePods, pod)
+		}
+	}
+	summary.addPods(nodePods)
+
+	metric, err := cs.Metric.NodeMetricses().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		klog.Warningf("failed to get metrics of node %s: %v", name, err)
+		return summary, nil
+	}
+	summary.Used.add(metric.Usage)
+	summary.MetricsAvailable = true
+	return summary, nil
+}
+
+// NamespaceResources 统计命名空间的资源，命名空间没有可分配总量，使用集群的可分配总量
+func (cs *ClusterSet) NamespaceResources(ctx context.Context, namespace string) (*ResourceSummary, error) {
+	namespaceLister, err := cs.Informer.NamespacesLister()
+	if err != nil {
+		return nil, err
+	}
+	if _, err = namespaceLister.Get(namespace); err != nil {
+		return nil, err
+	}
+	nodes, err := cs.listNodes()
+	if err != nil {
+		return nil, err
+	}
+	pods, err := cs.listPods(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &ResourceSummary{}
+	for _, node := range nodes {
+		summary.Capacity.add(node.Status.Allocatable)
+	}
+	summary.addPods(pods)
+
+	podMetrics, err := cs.Metric.PodMetricses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Warningf("failed to list pod metrics of namespace %s: %v", namespace, err)
+		return summary, nil
+	}
+	for _, metric := range podMetrics.Items {
+		for _, container := range metric.Containers {
+			summary.Used.add(container.Usage)
+		}
+	}
+	summary.MetricsAvailable = true
+	return summary, nil
+}
+
+// addPods 累加未结束的 pod 的申请量和限制量
+func (s *ResourceSummary) addPods(pods []*v1.Pod) {
+	for _, pod := range pods {
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		requests, limits := podRequestsAndLimits(pod)
+		s.Requested.add(requests)
+		s.Limits.add(limits)
+	}
+}
+
+// podRequestsAndLimits 与调度器的计算方式一致：
+// 取业务容器之和与单个 init 容器的最大值中较大者，再加上 pod 的 overhead
+func podRequestsAndLimits(pod *v1.Pod) (v1.ResourceList, v1.ResourceList) {
+	requests, limits := v1.ResourceList{}, v1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		addResourceList(requests, container.Resources.Requests)
+		addResourceList(limits, container.Resources.Limits)
+	}
+	for _, container := range pod.Spec.InitContainers {
+		maxResourceList(requests, container.Resources.Requests)
+		maxResourceList(limits, container.Resources.Limits)
+	}
+	if pod.Spec.Overhead != nil {
+		addResourceList(requests, pod.Spec.Overhead)
+		addResourceList(limits, pod.Spec.Overhead)
+	}
+	return requests, limits
+}
+
+func addResourceList(list, added v1.ResourceList) {
+	for name, quantity := range added {
+		if value, ok := list[name]; ok {
+			value.Add(quantity)
+			list[name] = value
+		} else {
+			list[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+func maxResourceList(list, other v1.ResourceList) {
+	for name, quantity := range other {
+		if value, ok := list[name]; !ok || quantity.Cmp(value) > 0 {
+			list[name] = quantity.DeepCopy()
+		}
+	}
+}
@@ -88,12 +88,14 @@ func (a *audit) model2Type(o *model.Audit) *types.Audit {
 			GmtCreate:   o.GmtCreate,
 			GmtModified: o.GmtModified,
 		},
+		RequestId:  o.RequestId,
 		Ip:         o.IP,
 		Action:     o.Action,
 		Status:     o.Status,
 		Operator:   o.Operator,
 		Path:       o.Path,
 		ObjectType: o.ObjectType,
+		Event:      o.Event,
 	}
 }
 
@@ -47,20 +47,36 @@ type (
 	}
 )
 
+// AccessSyncer 用户权限变更后同步集群内已签发 kubeConfig 的授权
+type AccessSyncer interface {
+	SyncUserAccess(ctx context.Context, userIds ...int64)
+}
+
 type auth struct {
 	enforcer *casbin.SyncedEnforcer
 	factory  db.ShareDaoFactory
+	syncer   AccessSyncer
 }
 
-func NewAuth(factory db.ShareDaoFactory, enforcer *casbin.SyncedEnforcer) Interface {
+func NewAuth(factory db.ShareDaoFactory, enforcer *casbin.SyncedEnforcer, syncer AccessSyncer) Interface {
 	return &auth{
 		factory:  factory,
 		enforcer: enforcer,
+		syncer:   syncer,
 	}
 }
 
 // getPolicy returns the RBAC policy represented by the request body
+// 只有 kubernetes 资源的策略可以限定命名空间
 func (a *auth) getPolicy(ctx context.Context, req *types.RBACPolicyRequest) (model.Policy, error) {
+	ns := req.Namespace
+	if len(ns) == 0 {
+		ns = model.NamespaceAll
+	}
+	if ns != model.NamespaceAll && !model.IsKubeObject(req.ObjectType) {
+		return nil, errors.NewError(fmt.Errorf("%s 不是 kubernetes 资源，不能限定命名空间", req.ObjectType), http.StatusBadRequest)
+	}
+
 	if req.UserId != nil {
 		// user RBAC policy
 		user, err := a.factory.User().Get(ctx, *req.UserId)
@@ -71,10 +87,10 @@ func (a *auth) getPolicy(ctx context.Context, req *types.RBACPolicyRequest) (mod
 		if user == nil {
 			return nil, errors.NewError(fmt.Errorf("user(%d) is not found", *req.UserId), http.StatusBadRequest)
 		}
-		return model.NewUserPolicy(user.Name, req.ObjectType, req.SID, req.Operation), nil
+		return model.NewUserPolicy(user.Name, req.ObjectType, req.SID, req.Operation, ns), nil
 	}
 	// group RBAC policy
-	return model.NewGroupPolicy(*req.GroupName, req.ObjectType, req.SID, req.Operation), nil
+	return model.NewGroupPolicy(*req.GroupName, req.ObjectType, req.SID, req.Operation, ns), nil
 }
 
 // getBinding returns the group binding policy  represented by the request body
@@ -114,6 +130,7 @@ func (a *auth) CreateRBACPolicy(ctx context.Context, req *types.RBACPolicyReques
 	if !ok {
 		return errors.ErrRBACPolicyExists
 	}
+	go a.syncPolicyUsers(policy)
 
 	return nil
 }
@@ -148,10 +165,44 @@ func (a *auth) DeleteRBACPolicy(ctx context.Context, req *types.RBACPolicyReques
 	if !ok {
 		return errors.ErrRBACPolicyNotFound
 	}
+	go a.syncPolicyUsers(policy)
 
 	return nil
 }
 
+// syncPolicyUsers 同步策略作用的用户在集群内的授权，用户组策略同步用户组内的全部用户
+func (a *auth) syncPolicyUsers(policy model.Policy) {
+	ctx := context.Background()
+
+	var userNames []string
+	switch p := policy.(type) {
+	case model.UserPolicy:
+		userNames = append(userNames, p.GetUserName())
+	case model.GroupPolicy:
+		bindings, err := ctrlutil.GetGroupBindings(a.enforcer, ctrlutil.QueryWithGroupName(p.GetGroupName()))
+		if err != nil {
+			klog.Errorf("failed to get bindings of group(%s): %v", p.GetGroupName(), err)
+			return
+		}
+		for _, binding := range bindings {
+			userNames = append(userNames, binding.GetUserName())
+		}
+	}
+
+	userIds := make([]int64, 0, len(userNames))
+	for _, name := range userNames {
+		user, err := a.factory.User().GetUserByName(ctx, name)
+		if err != nil {
+			klog.Errorf("failed to get user(%s): %v", name, err)
+			continue
+		}
+		if user != nil {
+			userIds = append(userIds, user.Id)
+		}
+	}
+	a.syncer.SyncUserAccess(ctx, userIds...)
+}
+
 func (a *auth) ListRBACPolicies(ctx context.Context, req *types.ListRBACPolicyRequest) ([]types.RBACPolicy, error) {
 	user, err := a.factory.User().Get(ctx, req.UserId)
 	if err != nil {
@@ -172,6 +223,9 @@ func (a *auth) ListRBACPolicies(ctx context.Context, req *types.ListRBACPolicyRe
 	if req.Operation != nil {
 		conds = append(conds, ctrlutil.WithOperation(*req.Operation))
 	}
+	if req.Namespace != nil {
+		conds = append(conds, ctrlutil.WithNamespace(*req.Namespace))
+	}
 
 	policies, err := ctrlutil.GetUserPolicies(a.enforcer, user, conds...)
 	if err != nil {
@@ -217,6 +271,7 @@ func (a *auth) CreateGroupBinding(ctx context.Context, req *types.GroupBindingRe
 	if !ok {
 		return errors.ErrGroupBindingExists
 	}
+	go a.syncer.SyncUserAccess(context.Background(), req.UserId)
 
 	return nil
 }
@@ -235,6 +290,7 @@ func (a *auth) DeleteGroupBinding(ctx context.Context, req *types.GroupBindingRe
 	if !ok {
 		return errors.ErrGroupBindingNotFound
 	}
+	go a.syncer.SyncUserAccess(context.Background(), req.UserId)
 
 	return nil
 }
@@ -296,6 +352,7 @@ func model2Type(policy model.Policy) *types.RBACPolicy {
 			ObjectType: p.GetObjectType(),
 			StringID:   p.GetSID(),
 			Operation:  p.GetOperation(),
+			Namespace:  p.GetNamespace(),
 		}
 	case model.GroupPolicy:
 		return &types.RBACPolicy{
@@ -303,6 +360,7 @@ func model2Type(policy model.Policy) *types.RBACPolicy {
 			ObjectType: p.GetObjectType(),
 			StringID:   p.GetSID(),
 			Operation:  p.GetOperation(),
+			Namespace:  p.GetNamespace(),
 		}
 	case model.GroupBinding:
 		return &types.RBACPolicy{
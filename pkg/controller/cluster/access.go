@@ -0,0 +1,631 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+
+	"kubevulpes/api/errors"
+	"kubevulpes/api/httputils"
+	"kubevulpes/pkg/client"
+	ctrlutil "kubevulpes/pkg/controller/util"
+	"kubevulpes/pkg/db/model"
+	"kubevulpes/pkg/types"
+	"kubevulpes/pkg/util/lru"
+)
+
+const (
+	// 用户 ServiceAccount 所在的命名空间
+	userAccessNamespace = "kubevulpes-system"
+	userIdLabel         = "kubevulpes.io/user-id"
+	managedByLabel      = "app.kubernetes.io/managed-by"
+	managedBy           = "kubevulpes"
+
+	// 代理请求扮演的用户和用户组的名称前缀，避免与集群内已有的用户重名
+	impersonatePrefix = "kubevulpes:"
+
+	// 同步单个集群内 RBAC 对象的超时时间
+	userAccessTimeout = 30 * time.Second
+	// 代理请求前同步授权的间隔，权限变更时会立即同步
+	proxyAccessTTL = time.Minute
+)
+
+// reconciledAccess 最近一次同步用户在集群内授权的时间
+var reconciledAccess = lru.NewLRUCache(1024)
+
+// userAccess 用户在集群内的权限，由用户自身和所属用户组的策略转换而来
+type userAccess struct {
+	// 不限定命名空间的权限，使用 ClusterRole 授权
+	clusterRules []rbacv1.PolicyRule
+	// 限定命名空间的权限，在每个命名空间内使用 Role 授权
+	namespaceRules map[string][]rbacv1.PolicyRule
+}
+
+func (a *userAccess) empty() bool {
+	return len(a.clusterRules) == 0 && len(a.namespaceRules) == 0
+}
+
+func userAccessName(userId int64) string {
+	return fmt.Sprintf("kubevulpes-user-%d", userId)
+}
+
+// impersonateUser 代理请求扮演的集群内用户
+func impersonateUser(userName string) string {
+	return impersonatePrefix + userName
+}
+
+func userAccessLabels(userId int64) map[string]string {
+	return map[string]string{
+		userIdLabel:    strconv.FormatInt(userId, 10),
+		managedByLabel: managedBy,
+	}
+}
+
+// UserKubeConfig 按照当前用户在 vulpes 中的权限，在集群内创建或者刷新 ServiceAccount 和 RoleBinding，
+// 并返回使用短期 token 的 kubeConfig
+func (c *cluster) UserKubeConfig(ctx context.Context, cid int64) (*types.UserKubeConfig, error) {
+	user, err := httputils.GetUserFromRequest(ctx)
+	if err != nil {
+		return nil, errors.NewError(err, http.StatusUnauthorized)
+	}
+	object, err := c.factory.Cluster().Get(ctx, cid)
+	if err != nil {
+		klog.Errorf("failed to get cluster(%d): %v", cid, err)
+		return nil, errors.ErrServerInternal
+	}
+	if object == nil {
+		return nil, errors.ErrClusterNotFound
+	}
+	cs, ok := clusterIndexer.Get(object.Name)
+	if !ok {
+		return nil, errors.NewError(fmt.Errorf("集群 %s 未就绪，请稍后重试", object.Name), http.StatusServiceUnavailable)
+	}
+
+	syncCtx, cancel := context.WithTimeout(ctx, userAccessTimeout)
+	defer cancel()
+	access, err := c.reconcileUserAccess(syncCtx, cs, object, user)
+	if err != nil {
+		return nil, err
+	}
+	if access.empty() {
+		return nil, errors.NewError(fmt.Errorf("没有集群 %s 内 kubernetes 资源的权限", object.Name), http.StatusForbidden)
+	}
+
+	seconds := int64(c.cc.KubeConfig.TokenTTL().Seconds())
+	token, err := cs.Client.CoreV1().ServiceAccounts(userAccessNamespace).CreateToken(syncCtx, userAccessName(user.Id), &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &seconds},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		klog.Errorf("failed to create token of user(%s) in cluster(%s): %v", user.Name, object.Name, err)
+		return nil, errors.NewError(fmt.Errorf("签发集群 %s 的 token 失败: %v", object.Name, err), http.StatusBadGateway)
+	}
+	kubeConfig, err := tokenKubeConfig(cs.Config, object.Name, user.Name, token.Status.Token)
+	if err != nil {
+		klog.Errorf("failed to build kubeConfig of user(%s) in cluster(%s): %v", user.Name, object.Name, err)
+		return nil, errors.ErrServerInternal
+	}
+
+	expire := token.Status.ExpirationTimestamp.Time
+	if err = c.factory.UserKubeConfig().Save(ctx, &model.UserKubeConfig{
+		UserId:    user.Id,
+		ClusterId: object.Id,
+		GmtExpire: expire,
+	}); err != nil {
+		klog.Errorf("failed to save kubeConfig record of user(%s) in cluster(%s): %v", user.Name, object.Name, err)
+		return nil, errors.ErrServerInternal
+	}
+
+	namespaces := make([]string, 0, len(access.namespaceRules))
+	for ns := range access.namespaceRules {
+		namespaces = append(namespaces, ns)
+	}
+	return &types.UserKubeConfig{
+		KubeConfig:          string(kubeConfig),
+		ExpirationTimestamp: expire,
+		ClusterScoped:       len(access.clusterRules) != 0,
+		Namespaces:          sets.NewString(namespaces...).List(),
+	}, nil
+}
+
+// SyncUserAccess 用户权限变更后，按照最新的权限同步已签发过 kubeConfig 的集群，没有权限时删除集群内的授权
+// 集群不可达时只记录日志，下次签发 kubeConfig 时重新同步
+func (c *cluster) SyncUserAccess(ctx context.Context, userIds ...int64) {
+	for _, uid := range userIds {
+		records, err := c.factory.UserKubeConfig().ListByUser(ctx, uid)
+		if err != nil {
+			klog.Errorf("failed to list kubeConfig records of user(%d): %v", uid, err)
+			continue
+		}
+		if len(records) == 0 {
+			continue
+		}
+		// 用户已被删除时撤销全部授权
+		user, err := c.factory.User().Get(ctx, uid)
+		if err != nil {
+			klog.Errorf("failed to get user(%d): %v", uid, err)
+			continue
+		}
+
+		for _, record := range records {
+			if err = c.syncUserAccess(ctx, record, user); err != nil {
+				klog.Errorf("failed to sync access of user(%d) in cluster(%d): %v", uid, record.ClusterId, err)
+			}
+		}
+	}
+}
+
+func (c *cluster) syncUserAccess(ctx context.Context, record model.UserKubeConfig, user *model.User) error {
+	object, err := c.factory.Cluster().Get(ctx, record.ClusterId)
+	if err != nil {
+		return err
+	}
+	if object == nil {
+		return c.factory.UserKubeConfig().Delete(ctx, record.UserId, record.ClusterId)
+	}
+	cs, ok := clusterIndexer.Get(object.Name)
+	if !ok {
+		return fmt.Errorf("cluster(%s) is not ready", object.Name)
+	}
+
+	syncCtx, cancel := context.WithTimeout(ctx, userAccessTimeout)
+	defer cancel()
+	if user != nil {
+		_, err = c.reconcileUserAccess(syncCtx, cs, object, user)
+		return err
+	}
+	if err = applyUserAccess(syncCtx, cs.Client, record.UserId, "", &userAccess{}); err != nil {
+		return err
+	}
+	return c.factory.UserKubeConfig().Delete(ctx, record.UserId, record.ClusterId)
+}
+
+// reconcileUserAccess 按照用户当前的权限同步集群内的授权，并记录同步过的集群，权限变更后只同步记录中的集群
+func (c *cluster) reconcileUserAccess(ctx context.Context, cs client.ClusterSet, object *model.Cluster, user *model.User) (*userAccess, error) {
+	access, err := c.buildUserAccess(ctx, cs, object, user)
+	if err != nil {
+		klog.Errorf("failed to build access of user(%s) in cluster(%s): %v", user.Name, object.Name, err)
+		return nil, errors.ErrServerInternal
+	}
+	if err = applyUserAccess(ctx, cs.Client, user.Id, user.Name, access); err != nil {
+		klog.Errorf("failed to apply access of user(%s) in cluster(%s): %v", user.Name, object.Name, err)
+		return nil, errors.NewError(fmt.Errorf("同步集群 %s 内的权限失败: %v", object.Name, err), http.StatusBadGateway)
+	}
+
+	if access.empty() {
+		err = c.factory.UserKubeConfig().Delete(ctx, user.Id, object.Id)
+	} else {
+		err = c.factory.UserKubeConfig().Save(ctx, &model.UserKubeConfig{
+			UserId:    user.Id,
+			ClusterId: object.Id,
+			GmtExpire: time.Now(),
+		})
+	}
+	if err != nil {
+		klog.Errorf("failed to save kubeConfig record of user(%s) in cluster(%s): %v", user.Name, object.Name, err)
+		return nil, errors.ErrServerInternal
+	}
+	reconciledAccess.Add(reconciledKey(user.Id, object.Id), time.Now())
+	return access, nil
+}
+
+// ensureUserAccess 代理请求前确保集群内的授权已经同步，同步结果缓存 proxyAccessTTL
+func (c *cluster) ensureUserAccess(ctx context.Context, cs client.ClusterSet, object *model.Cluster, user *model.User) error {
+	if synced, ok := reconciledAccess.Get(reconciledKey(user.Id, object.Id)).(time.Time); ok && time.Since(synced) < proxyAccessTTL {
+		return nil
+	}
+
+	syncCtx, cancel := context.WithTimeout(ctx, userAccessTimeout)
+	defer cancel()
+	_, err := c.reconcileUserAccess(syncCtx, cs, object, user)
+	return err
+}
+
+func reconciledKey(userId, clusterId int64) string {
+	return fmt.Sprintf("%d/%d", userId, clusterId)
+}
+
+// buildUserAccess 将作用于集群的 kubernetes 资源策略转换为 RBAC 规则
+// 策略中的命名空间为正则表达式时按照集群当前的命名空间展开，新建的命名空间在下次同步时生效
+func (c *cluster) buildUserAccess(ctx context.Context, cs client.ClusterSet, object *model.Cluster, user *model.User) (*userAccess, error) {
+	bindings, err := ctrlutil.GetGroupBindings(c.enforcer, ctrlutil.QueryWithUserName(user.Name))
+	if err != nil {
+		return nil, err
+	}
+	if model.BindingToAdmin(bindings) {
+		return &userAccess{clusterRules: []rbacv1.PolicyRule{
+			{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+			{NonResourceURLs: []string{"*"}, Verbs: []string{"*"}},
+		}}, nil
+	}
+	policies, err := ctrlutil.GetUserAndGroupPolicies(c.enforcer, user, bindings)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := &resourceResolver{discovery: cs.Client.Discovery()}
+	clusterRules := ruleSet{}
+	namespaced := make(map[string]ruleSet)
+	for _, policy := range policies {
+		raw := policy.Raw() // e.g. ["foo", "deployments", "prod", "update", "team-a"]
+		if len(raw) < 4 {
+			continue
+		}
+		obj, sid, op, ns := model.ObjectType(raw[1]), raw[2], raw[3], model.NamespaceAll
+		if len(raw) > 4 {
+			ns = raw[4]
+		}
+		if !(model.IsKubeObject(obj) || obj == model.ObjectAll) || !model.KeyMatch(object.Name, sid) {
+			continue
+		}
+		resources, err := resolver.resolve(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		rules := clusterRules
+		if ns != model.NamespaceAll {
+			if namespaced[ns] == nil {
+				namespaced[ns] = ruleSet{}
+			}
+			rules = namespaced[ns]
+		}
+		for _, resource := range resources {
+			rules.addPolicy(resource, op)
+		}
+	}
+
+	access := &userAccess{
+		clusterRules:   clusterRules.rules(),
+		namespaceRules: make(map[string][]rbacv1.PolicyRule),
+	}
+	if len(namespaced) == 0 {
+		return access, nil
+	}
+	namespaces, err := cs.Client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, namespace := range namespaces.Items {
+		rules := ruleSet{}
+		for pattern, rs := range namespaced {
+			if model.KeyMatch(namespace.Name, pattern) {
+				rules.merge(rs)
+			}
+		}
+		if len(rules) != 0 {
+			access.namespaceRules[namespace.Name] = rules.rules()
+		}
+	}
+	return access, nil
+}
+
+// operationVerbs vulpes 的操作对应的 kubernetes 动作，exec 只作用于子资源
+var operationVerbs = []struct {
+	op    model.Operation
+	verbs []string
+}{
+	{model.OpRead, []string{"get", "list", "watch"}},
+	{model.OpCreate, []string{"create"}},
+	{model.OpUpdate, []string{"update", "patch"}},
+	{model.OpDelete, []string{"delete", "deletecollection"}},
+	{model.OpExec, []string{"create", "get"}},
+}
+
+// subresources 与 vulpes 接口对应的子资源，例如查看日志、扩缩容、驱逐和终端，驱逐与接口的鉴权一致需要删除权限
+var subresources = map[model.Operation]map[model.ObjectType][]string{
+	model.OpRead:   {model.ObjectPod: {"pods/log"}},
+	model.OpUpdate: {model.ObjectDeployment: {"deployments/scale"}, model.ObjectStatefulSet: {"statefulsets/scale"}},
+	model.OpDelete: {model.ObjectPod: {"pods/eviction"}},
+	model.OpExec:   {model.ObjectPod: {"pods/exec", "pods/attach"}},
+}
+
+// subresourceVerbs 与操作的动作不一致的子资源，驱逐在集群内是对 pods/eviction 的 create
+var subresourceVerbs = map[string][]string{
+	"pods/eviction": {"create"},
+}
+
+// kubeObjectGroups vulpes 已知的 kubernetes 资源所在的 API 组
+// 对象为 * 的策略只授权这些资源，secrets 等 vulpes 不提供的资源需要单独授权
+var kubeObjectGroups = map[model.ObjectType]string{
+	model.ObjectNamespace:   "",
+	model.ObjectNode:        "",
+	model.ObjectEvent:       "",
+	model.ObjectPod:         "",
+	model.ObjectDeployment:  "apps",
+	model.ObjectStatefulSet: "apps",
+	model.ObjectDaemonSet:   "apps",
+	model.ObjectCronJob:     "batch",
+	model.ObjectJob:         "batch",
+}
+
+// resourceResolver 将策略的对象转换为集群内的资源，未知的资源（例如 CRD）通过 discovery 查找所在的 API 组
+type resourceResolver struct {
+	discovery discovery.DiscoveryInterface
+	// 资源的复数名称对应的 API 组，第一次查询未知资源时加载
+	groups map[string][]string
+}
+
+func (r *resourceResolver) resolve(obj model.ObjectType) ([]schema.GroupResource, error) {
+	if obj == model.ObjectAll {
+		resources := make([]schema.GroupResource, 0, len(kubeObjectGroups))
+		for o, group := range kubeObjectGroups {
+			resources = append(resources, schema.GroupResource{Group: group, Resource: o.String()})
+		}
+		return resources, nil
+	}
+	if group, ok := kubeObjectGroups[obj]; ok {
+		return []schema.GroupResource{{Group: group, Resource: obj.String()}}, nil
+	}
+
+	if r.groups == nil {
+		lists, err := r.discovery.ServerPreferredResources()
+		// 部分 API 组不可用时仍然使用已发现的资源
+		if err != nil && len(lists) == 0 {
+			return nil, err
+		}
+		r.groups = make(map[string][]string)
+		for _, list := range lists {
+			gv, err := schema.ParseGroupVersion(list.GroupVersion)
+			if err != nil {
+				continue
+			}
+			for _, resource := range list.APIResources {
+				if !strings.Contains(resource.Name, "/") {
+					r.groups[resource.Name] = append(r.groups[resource.Name], gv.Group)
+				}
+			}
+		}
+	}
+	resources := make([]schema.GroupResource, 0)
+	for _, group := range r.groups[obj.String()] {
+		resources = append(resources, schema.GroupResource{Group: group, Resource: obj.String()})
+	}
+	return resources, nil
+}
+
+// ruleSet 按照资源合并授权的动作
+type ruleSet map[schema.GroupResource]sets.String
+
+func (r ruleSet) add(verbs []string, group string, resources ...string) {
+	for _, resource := range resources {
+		gr := schema.GroupResource{Group: group, Resource: resource}
+		if r[gr] == nil {
+			r[gr] = sets.NewString()
+		}
+		r[gr].Insert(verbs...)
+	}
+}
+
+func (r ruleSet) merge(other ruleSet) {
+	for gr, verbs := range other {
+		r.add(verbs.List(), gr.Group, gr.Resource)
+	}
+}
+
+// addPolicy 按照策略的操作授权资源及其对应的子资源
+func (r ruleSet) addPolicy(resource schema.GroupResource, op string) {
+	for _, ov := range operationVerbs {
+		if !model.KeyMatch(ov.op.String(), op) {
+			continue
+		}
+		if ov.op != model.OpExec {
+			r.add(ov.verbs, resource.Group, resource.Resource)
+		}
+		for _, sub := range subresources[ov.op][model.ObjectType(resource.Resource)] {
+			verbs, ok := subresourceVerbs[sub]
+			if !ok {
+				verbs = ov.verbs
+			}
+			r.add(verbs, resource.Group, sub)
+		}
+	}
+}
+
+func (r ruleSet) rules() []rbacv1.PolicyRule {
+	resources := make([]schema.GroupResource, 0, len(r))
+	for gr := range r {
+		resources = append(resources, gr)
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].Group != resources[j].Group {
+			return resources[i].Group < resources[j].Group
+		}
+		return resources[i].Resource < resources[j].Resource
+	})
+
+	rules := make([]rbacv1.PolicyRule, 0, len(r))
+	for _, gr := range resources {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{gr.Group},
+			Resources: []string{gr.Resource},
+			Verbs:     r[gr].List(),
+		})
+	}
+	return rules
+}
+
+// applyUserAccess 使集群内用户的 ServiceAccount、角色和绑定与 access 保持一致，access 为空时全部删除
+// 绑定的主体同时包含代理请求扮演的用户，使通过 vulpes 代理的请求拥有相同的权限
+func applyUserAccess(ctx context.Context, c kubernetes.Interface, userId int64, userName string, access *userAccess) error {
+	name, labels := userAccessName(userId), userAccessLabels(userId)
+	if !access.empty() {
+		if err := ensureServiceAccount(ctx, c, name, labels); err != nil {
+			return err
+		}
+	}
+
+	subjects := []rbacv1.Subject{
+		{Kind: rbacv1.ServiceAccountKind, Name: name, Namespace: userAccessNamespace},
+		{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: impersonateUser(userName)},
+	}
+	if len(access.clusterRules) != 0 {
+		if err := applyClusterRole(ctx, c, name, labels, access.clusterRules, subjects); err != nil {
+			return err
+		}
+	} else {
+		if err := ignoreNotFound(c.RbacV1().ClusterRoleBindings().Delete(ctx, name, metav1.DeleteOptions{})); err != nil {
+			return err
+		}
+		if err := ignoreNotFound(c.RbacV1().ClusterRoles().Delete(ctx, name, metav1.DeleteOptions{})); err != nil {
+			return err
+		}
+	}
+
+	for ns, rules := range access.namespaceRules {
+		if err := applyRole(ctx, c, ns, name, labels, rules, subjects); err != nil {
+			return err
+		}
+	}
+	// 删除不再授权的命名空间内的角色和绑定
+	bindings, err := c.RbacV1().RoleBindings(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: userIdLabel + "=" + labels[userIdLabel],
+	})
+	if err != nil {
+		return err
+	}
+	for _, binding := range bindings.Items {
+		if _, ok := access.namespaceRules[binding.Namespace]; ok {
+			continue
+		}
+		if err = ignoreNotFound(c.RbacV1().RoleBindings(binding.Namespace).Delete(ctx, binding.Name, metav1.DeleteOptions{})); err != nil {
+			return err
+		}
+		if err = ignoreNotFound(c.RbacV1().Roles(binding.Namespace).Delete(ctx, binding.RoleRef.Name, metav1.DeleteOptions{})); err != nil {
+			return err
+		}
+	}
+
+	if access.empty() {
+		// 删除 ServiceAccount 后已签发的 token 立即失效
+		return ignoreNotFound(c.CoreV1().ServiceAccounts(userAccessNamespace).Delete(ctx, name, metav1.DeleteOptions{}))
+	}
+	return nil
+}
+
+func ensureServiceAccount(ctx context.Context, c kubernetes.Interface, name string, labels map[string]string) error {
+	if _, err := c.CoreV1().Namespaces().Create(ctx, &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: userAccessNamespace, Labels: map[string]string{managedByLabel: managedBy}},
+	}, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	if _, err := c.CoreV1().ServiceAccounts(userAccessNamespace).Create(ctx, &v1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+	}, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+func applyClusterRole(ctx context.Context, c kubernetes.Interface, name string, labels map[string]string, rules []rbacv1.PolicyRule, subjects []rbacv1.Subject) error {
+	role, err := c.RbacV1().ClusterRoles().Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		role = &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}, Rules: rules}
+		_, err = c.RbacV1().ClusterRoles().Create(ctx, role, metav1.CreateOptions{})
+	case err == nil:
+		role.Labels, role.Rules = labels, rules
+		_, err = c.RbacV1().ClusterRoles().Update(ctx, role, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+
+	binding, err := c.RbacV1().ClusterRoleBindings().Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		binding = &rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: name},
+			Subjects:   subjects,
+		}
+		_, err = c.RbacV1().ClusterRoleBindings().Create(ctx, binding, metav1.CreateOptions{})
+	case err == nil:
+		binding.Labels, binding.Subjects = labels, subjects
+		_, err = c.RbacV1().ClusterRoleBindings().Update(ctx, binding, metav1.UpdateOptions{})
+	}
+	return err
+}
+
+func applyRole(ctx context.Context, c kubernetes.Interface, namespace, name string, labels map[string]string, rules []rbacv1.PolicyRule, subjects []rbacv1.Subject) error {
+	role, err := c.RbacV1().Roles(namespace).Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		role = &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels}, Rules: rules}
+		_, err = c.RbacV1().Roles(namespace).Create(ctx, role, metav1.CreateOptions{})
+	case err == nil:
+		role.Labels, role.Rules = labels, rules
+		_, err = c.RbacV1().Roles(namespace).Update(ctx, role, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+
+	binding, err := c.RbacV1().RoleBindings(namespace).Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		binding = &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: name},
+			Subjects:   subjects,
+		}
+		_, err = c.RbacV1().RoleBindings(namespace).Create(ctx, binding, metav1.CreateOptions{})
+	case err == nil:
+		binding.Labels, binding.Subjects = labels, subjects
+		_, err = c.RbacV1().RoleBindings(namespace).Update(ctx, binding, metav1.UpdateOptions{})
+	}
+	return err
+}
+
+func ignoreNotFound(err error) error {
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// tokenKubeConfig 使用集群的 API 地址和 CA 证书构造用户的 kubeConfig
+func tokenKubeConfig(config *restclient.Config, clusterName, userName, token string) ([]byte, error) {
+	config = restclient.CopyConfig(config)
+	if err := restclient.LoadTLSFiles(config); err != nil {
+		return nil, err
+	}
+	credential := &client.Credential{
+		Server:                config.Host,
+		CertificateAuthority:  config.CAData,
+		InsecureSkipTLSVerify: config.Insecure,
+		Token:                 token,
+	}
+	return credential.Write(clusterName, userName)
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	casbinmodel "github.com/casbin/casbin/v2/model"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+
+	"kubevulpes/pkg/client"
+	"kubevulpes/pkg/db/model"
+)
+
+// newTestApiServer 模拟 apiserver 的 discovery 和命名空间接口，集群内有 example.com/v1 的 widgets CRD
+func newTestApiServer(t *testing.T) *kubernetes.Clientset {
+	responses := map[string]interface{}{
+		"/api": &metav1.APIVersions{Versions: []string{"v1"}},
+		"/api/v1": &metav1.APIResourceList{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", Namespaced: true, Kind: "Pod", Verbs: []string{"get", "list"}},
+				{Name: "secrets", Namespaced: true, Kind: "Secret", Verbs: []string{"get", "list"}},
+			},
+		},
+		"/apis": &metav1.APIGroupList{Groups: []metav1.APIGroup{{
+			Name:             "example.com",
+			Versions:         []metav1.GroupVersionForDiscovery{{GroupVersion: "example.com/v1", Version: "v1"}},
+			PreferredVersion: metav1.GroupVersionForDiscovery{GroupVersion: "example.com/v1", Version: "v1"},
+		}}},
+		"/apis/example.com/v1": &metav1.APIResourceList{
+			GroupVersion: "example.com/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "widgets", Namespaced: true, Kind: "Widget", Verbs: []string{"get", "list"}},
+				{Name: "widgets/status", Namespaced: true, Kind: "Widget", Verbs: []string{"get"}},
+			},
+		},
+		"/api/v1/namespaces": &v1.NamespaceList{
+			TypeMeta: metav1.TypeMeta{Kind: "NamespaceList", APIVersion: "v1"},
+			Items: []v1.Namespace{
+				{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+			},
+		},
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(ts.Close)
+
+	cs, err := kubernetes.NewForConfig(&restclient.Config{Host: ts.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cs
+}
+
+func newTestEnforcer(t *testing.T, policies []model.Policy, bindings []model.GroupBinding) *casbin.SyncedEnforcer {
+	m, err := casbinmodel.NewModelFromString(model.RBACModel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enforcer, err := casbin.NewSyncedEnforcer(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enforcer.AddFunction("keyMatch2", model.CustomKeyMatch)
+	for _, policy := range policies {
+		if _, err = enforcer.AddPolicy(policy.Raw()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, binding := range bindings {
+		if _, err = enforcer.AddGroupingPolicy(binding.Raw()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return enforcer
+}
+
+func rule(group, resource string, verbs ...string) rbacv1.PolicyRule {
+	return rbacv1.PolicyRule{APIGroups: []string{group}, Resources: []string{resource}, Verbs: verbs}
+}
+
+func TestBuildUserAccess(t *testing.T) {
+	readVerbs := []string{"get", "list", "watch"}
+	cases := []struct {
+		name           string
+		policies       []model.Policy
+		bindings       []model.GroupBinding
+		clusterRules   []rbacv1.PolicyRule
+		namespaceRules map[string][]rbacv1.PolicyRule
+	}{
+		{
+			name:     "admin",
+			bindings: []model.GroupBinding{{"foo", model.AdminGroup}},
+			clusterRules: []rbacv1.PolicyRule{
+				{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+				{NonResourceURLs: []string{"*"}, Verbs: []string{"*"}},
+			},
+		},
+		{
+			name:     "read pods",
+			policies: []model.Policy{model.NewUserPolicy("foo", model.ObjectPod, "prod", model.OpRead, model.NamespaceAll)},
+			clusterRules: []rbacv1.PolicyRule{
+				rule("", "pods", readVerbs...),
+				rule("", "pods/log", readVerbs...),
+			},
+		},
+		{
+			name:     "all objects exclude secrets",
+			policies: []model.Policy{model.NewUserPolicy("foo", model.ObjectAll, "prod", model.OpRead, model.NamespaceAll)},
+			clusterRules: []rbacv1.PolicyRule{
+				rule("", "events", readVerbs...),
+				rule("", "namespaces", readVerbs...),
+				rule("", "nodes", readVerbs...),
+				rule("", "pods", readVerbs...),
+				rule("", "pods/log", readVerbs...),
+				rule("apps", "daemonsets", readVerbs...),
+				rule("apps", "deployments", readVerbs...),
+				rule("apps", "statefulsets", readVerbs...),
+				rule("batch", "cronjobs", readVerbs...),
+				rule("batch", "jobs", readVerbs...),
+			},
+		},
+		{
+			name:     "namespace pattern",
+			policies: []model.Policy{model.NewUserPolicy("foo", model.ObjectDeployment, "prod", model.OpUpdate, "team-.*")},
+			namespaceRules: map[string][]rbacv1.PolicyRule{
+				"team-a": {rule("apps", "deployments", "patch", "update"), rule("apps", "deployments/scale", "patch", "update")},
+				"team-b": {rule("apps", "deployments", "patch", "update"), rule("apps", "deployments/scale", "patch", "update")},
+			},
+		},
+		{
+			name:     "delete pods allows eviction",
+			policies: []model.Policy{model.NewUserPolicy("foo", model.ObjectPod, "prod", model.OpDelete, "default")},
+			namespaceRules: map[string][]rbacv1.PolicyRule{
+				"default": {rule("", "pods", "delete", "deletecollection"), rule("", "pods/eviction", "create")},
+			},
+		},
+		{
+			name:     "group policy grants exec only on subresources",
+			policies: []model.Policy{model.NewGroupPolicy("ops", model.ObjectPod, "*", model.OpExec, model.NamespaceAll)},
+			bindings: []model.GroupBinding{{"foo", "ops"}},
+			clusterRules: []rbacv1.PolicyRule{
+				rule("", "pods/attach", "create", "get"),
+				rule("", "pods/exec", "create", "get"),
+			},
+		},
+		{
+			name:         "custom resource",
+			policies:     []model.Policy{model.NewUserPolicy("foo", model.ObjectType("widgets"), "prod", model.OpRead, model.NamespaceAll)},
+			clusterRules: []rbacv1.PolicyRule{rule("example.com", "widgets", readVerbs...)},
+		},
+		{
+			name: "other clusters and vulpes objects",
+			policies: []model.Policy{
+				model.NewUserPolicy("foo", model.ObjectPod, "dev", model.OpRead, model.NamespaceAll),
+				model.NewUserPolicy("foo", model.ObjectHost, "*", model.OpAll, model.NamespaceAll),
+				model.NewUserPolicy("bar", model.ObjectPod, "prod", model.OpRead, model.NamespaceAll),
+			},
+		},
+	}
+
+	cs := client.ClusterSet{Client: newTestApiServer(t)}
+	object := &model.Cluster{Name: "prod"}
+	user := &model.User{Name: "foo"}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &cluster{enforcer: newTestEnforcer(t, tc.policies, tc.bindings)}
+			access, err := c.buildUserAccess(context.Background(), cs, object, user)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(access.clusterRules) != 0 || len(tc.clusterRules) != 0 {
+				if !reflect.DeepEqual(access.clusterRules, tc.clusterRules) {
+					t.Errorf("expected cluster rules %v, got %v", tc.clusterRules, access.clusterRules)
+				}
+			}
+			if len(access.namespaceRules) != 0 || len(tc.namespaceRules) != 0 {
+				if !reflect.DeepEqual(access.namespaceRules, tc.namespaceRules) {
+					t.Errorf("expected namespace rules %v, got %v", tc.namespaceRules, access.namespaceRules)
+				}
+			}
+		})
+	}
+}
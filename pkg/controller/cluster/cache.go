@@ -0,0 +1,121 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"kubevulpes/pkg/client"
+	"kubevulpes/pkg/db/model"
+)
+
+const (
+	// 集群缓存构建失败后的重试间隔，按指数退避直到最大值
+	minRetryInterval = 10 * time.Second
+	maxRetryInterval = 5 * time.Minute
+)
+
+// Load 从数据库加载全部集群，并在后台为每个集群构建 clusterSet
+// 每个集群独立重试，单个集群不可达不会阻塞服务启动
+func (c *cluster) Load(ctx context.Context) error {
+	objects, _, err := c.factory.Cluster().List(ctx)
+	if err != nil {
+		klog.Errorf("failed to list clusters: %v", err)
+		return err
+	}
+
+	for i := range objects {
+		object := objects[i]
+		if _, ok := clusterIndexer.Get(object.Name); ok {
+			continue
+		}
+		go c.buildClusterSet(ctx, &object)
+	}
+	return nil
+}
+
+// buildClusterSet 持续尝试构建集群的 clusterSet，直到成功、集群被删除或者 ctx 结束
+func (c *cluster) buildClusterSet(ctx context.Context, object *model.Cluster) {
+	c.setStatus(ctx, object, model.ClusterStatusConnecting, "正在构建集群缓存")
+
+	interval := minRetryInterval
+	for {
+		cs, err := c.newClusterSet(ctx, object)
+		if err == nil {
+			// 构建期间集群可能已经被重新导入，避免覆盖并泄露 informer
+			if _, ok := clusterIndexer.Get(object.Name); ok {
+				cs.Informer.Stop()
+				return
+			}
+			clusterIndexer.Set(object.Name, *cs)
+			c.setStatus(ctx, object, model.ClusterStatusRunning, "集群缓存构建完成")
+			klog.Infof("cache of cluster(%s) is ready", object.Name)
+			return
+		}
+
+		klog.Errorf("failed to build cache of cluster(%s), retry after %v: %v", object.Name, interval, err)
+		c.setStatus(ctx, object, model.ClusterStatusFailed, err.Error())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+		if interval *= 2; interval > maxRetryInterval {
+			interval = maxRetryInterval
+		}
+
+		// 重试前确认集群仍然存在，并使用最新的 kubeConfig
+		latest, err := c.factory.Cluster().Get(ctx, object.Id)
+		if err != nil {
+			klog.Errorf("failed to get cluster(%d): %v", object.Id, err)
+			continue
+		}
+		if latest == nil {
+			return
+		}
+		object = latest
+	}
+}
+
+func (c *cluster) newClusterSet(ctx context.Context, object *model.Cluster) (*client.ClusterSet, error) {
+	// 数据库中已有的 kubeConfig 同样需要检查，避免加载时执行 exec 插件或者读取本地文件
+	kubeConfig, err := client.SanitizeKubeConfig(object.KubeConfig, c.kubeConfigPolicy())
+	if err != nil {
+		return nil, err
+	}
+	// 先检查连通性，避免 informer 长时间等待不可达的集群
+	if err = c.Ping(ctx, kubeConfig); err != nil {
+		return nil, err
+	}
+	return client.NewClusterSet(kubeConfig, informerResources(object))
+}
+
+// setStatus 更新集群状态，状态变化时记录变更原因
+func (c *cluster) setStatus(ctx context.Context, object *model.Cluster, status model.ClusterStatus, message string) {
+	if err := c.factory.Cluster().UpdateStatus(ctx, object, status, message); err != nil {
+		klog.Errorf("failed to update status of cluster(%d): %v", object.Id, err)
+	}
+}
+
+// Indexer 返回全局的集群缓存，供后台任务使用
+func Indexer() *client.Cache {
+	return &clusterIndexer
+}
@@ -18,11 +18,16 @@ package cluster
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"regexp"
+	"strconv"
+	"time"
 
 	"github.com/casbin/casbin/v2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/klog/v2"
 
 	"kubevulpes/api/errors"
@@ -32,6 +37,8 @@ import (
 	"kubevulpes/pkg/db"
 	"kubevulpes/pkg/db/model"
 	"kubevulpes/pkg/types"
+	"kubevulpes/pkg/util"
+	utilerrors "kubevulpes/pkg/util/errors"
 	"kubevulpes/pkg/util/uuid"
 )
 
@@ -41,12 +48,43 @@ type ClusterGetter interface {
 
 type Interface interface {
 	Create(ctx context.Context, req *types.CreateClusterRequest) error
+	PreviewKubeConfig(ctx context.Context, req *types.PreviewKubeConfigRequest) ([]types.KubeConfigContext, error)
+	Import(ctx context.Context, req *types.ImportClustersRequest) ([]types.ImportClusterResult, error)
 	Update(ctx context.Context, clusterId int64, req *types.UpdateClusterRequest) error
 	Delete(ctx context.Context, clusterId int64) error
+	// Restore 恢复保留期内已删除的集群
+	Restore(ctx context.Context, clusterId int64) error
+	// ListDeleted 获取已删除但尚未清理的集群
+	ListDeleted(ctx context.Context, listOptions *types.ListOptions) (*types.PageResponse, error)
+	Protect(ctx context.Context, clusterId int64, req *types.ProtectClusterRequest) error
+	UpdateKubeConfig(ctx context.Context, clusterId int64, req *types.UpdateClusterKubeConfigRequest) error
+	GetInformers(ctx context.Context, clusterId int64) ([]types.ClusterInformer, error)
+	UpdateInformers(ctx context.Context, clusterId int64, req *types.UpdateClusterInformersRequest) error
 	Get(ctx context.Context, clusterId int64) (*types.Cluster, error)
 	List(ctx context.Context, listOptions *types.ListOptions) (*types.PageResponse, error)
+
+	// UserKubeConfig 为当前用户签发 kubeConfig，权限与用户在 vulpes 中的权限一致
+	UserKubeConfig(ctx context.Context, clusterId int64) (*types.UserKubeConfig, error)
+	// SyncUserAccess 用户权限变更后同步集群内的授权
+	SyncUserAccess(ctx context.Context, userIds ...int64)
+	// Proxy 以当前用户的身份将请求转发至集群的 apiserver
+	Proxy(ctx context.Context, clusterId int64, apiPath string) (http.Handler, error)
+
+	// Load 从数据库恢复集群缓存
+	Load(ctx context.Context) error
+
+	ListStatusRecords(ctx context.Context, clusterId int64, listOptions *types.ListOptions) (*types.PageResponse, error)
 }
 
+const (
+	// 集群详情统计资源时等待缓存同步的超时时间
+	resourcesSyncTimeout = 5 * time.Second
+
+	maxClusterNameLength = 63
+)
+
+var clusterNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9]([-a-zA-Z0-9]*[a-zA-Z0-9])?$`)
+
 type cluster struct {
 	cc       config.Config
 	factory  db.ShareDaoFactory
@@ -65,17 +103,32 @@ func (c *cluster) Create(ctx context.Context, req *types.CreateClusterRequest) e
 	//	return errors.NewError(err, http.StatusInternalServerError)
 	//}
 
-	if err := c.preCreate(ctx, req); err != nil {
+	// 使用凭证导入的集群转换为 kubeConfig，后续流程与 kubeConfig 导入一致
+	// 连接集群前检查 kubeConfig，拒绝 exec 插件和本地文件引用等不安全的配置
+	kubeConfig, err := c.resolveKubeConfig(req.KubeConfig, req.Credential)
+	if err != nil {
 		return errors.NewError(err, http.StatusBadRequest)
 	}
-	// TODO: 集群名称必须是由英文，数字组成
+	req.KubeConfig = kubeConfig
+
 	if len(req.Name) == 0 {
 		req.Name = uuid.NewRandName(8)
 	}
+	if err = c.validateName(ctx, req.Name); err != nil {
+		return err
+	}
+
+	resources, err := client.ParseResources(req.InformerResources)
+	if err != nil {
+		return errors.NewError(err, http.StatusBadRequest)
+	}
+	if err = c.preCreate(ctx, req, resources); err != nil {
+		return errors.NewError(err, http.StatusBadRequest)
+	}
 
 	var cs *client.ClusterSet
 	var txFunc = func(cluster *model.Cluster) (err error) {
-		if cs, err = client.NewClusterSet(req.KubeConfig); err != nil {
+		if cs, err = client.NewClusterSet(req.KubeConfig, resources); err != nil {
 			return
 		}
 
@@ -85,10 +138,17 @@ func (c *cluster) Create(ctx context.Context, req *types.CreateClusterRequest) e
 		return
 	}
 
-	if _, err := c.factory.Cluster().Create(ctx, &model.Cluster{
-		Name:       req.Name,
-		KubeConfig: req.KubeConfig,
+	if _, err = c.factory.Cluster().Create(ctx, &model.Cluster{
+		Name:              req.Name,
+		AliasName:         req.AliasName,
+		Description:       req.Description,
+		Protected:         req.Protected,
+		KubeConfig:        req.KubeConfig,
+		InformerResources: marshalInformerResources(req.InformerResources),
 	}, txFunc); err != nil {
+		if cs != nil {
+			cs.Informer.Stop()
+		}
 		return errors.NewError(err, http.StatusInternalServerError)
 	}
 
@@ -112,6 +172,11 @@ func (c *cluster) Update(ctx context.Context, cid int64, req *types.UpdateCluste
 	if req.Description != nil {
 		updates["description"] = *req.Description
 	}
+	if req.Labels != nil {
+		if updates["labels"], err = ctrlutil.MarshalLabels(*req.Labels); err != nil {
+			return errors.NewError(err, http.StatusBadRequest)
+		}
+	}
 	if len(updates) == 0 {
 		return errors.ErrInvalidRequest
 	}
@@ -123,32 +188,124 @@ func (c *cluster) Update(ctx context.Context, cid int64, req *types.UpdateCluste
 	return nil
 }
 
-func (c *cluster) Delete(ctx context.Context, cid int64) error {
-	//user, err := httputils.GetUserFromRequest(ctx)
-	//if err != nil {
-	//	return errors.NewError(err, http.StatusInternalServerError)
-	//}
+// Protect 开启或者关闭集群删除保护
+func (c *cluster) Protect(ctx context.Context, cid int64, req *types.ProtectClusterRequest) error {
+	object, err := c.factory.Cluster().Get(ctx, cid)
+	if err != nil {
+		klog.Errorf("failed to get cluster(%d): %v", cid, err)
+		return errors.ErrServerInternal
+	}
+	if object == nil {
+		return errors.ErrClusterNotFound
+	}
+
+	if err = c.factory.Cluster().Update(ctx, cid, *req.ResourceVersion, map[string]interface{}{
+		"protected": req.Protected,
+	}); err != nil {
+		klog.Errorf("failed to update protection of cluster(%d): %v", cid, err)
+		return errors.ErrServerInternal
+	}
+	return nil
+}
 
+// UpdateKubeConfig 更新集群的 kubeConfig，支持使用凭证更新
+// 新的 kubeConfig 必须能连通集群，构建新的 clusterSet 后替换缓存并停止旧的 informer
+func (c *cluster) UpdateKubeConfig(ctx context.Context, cid int64, req *types.UpdateClusterKubeConfigRequest) error {
+	object, err := c.factory.Cluster().Get(ctx, cid)
+	if err != nil {
+		klog.Errorf("failed to get cluster(%d): %v", cid, err)
+		return errors.ErrServerInternal
+	}
+	if object == nil {
+		return errors.ErrClusterNotFound
+	}
+
+	if req.KubeConfig, err = c.resolveKubeConfig(req.KubeConfig, req.Credential); err != nil {
+		return errors.NewError(err, http.StatusBadRequest)
+	}
+	if err = c.Ping(ctx, req.KubeConfig); err != nil {
+		return errors.NewError(fmt.Errorf("尝试连接 kubernetes API 失败: %v", err), http.StatusBadRequest)
+	}
+	cs, err := client.NewClusterSet(req.KubeConfig, informerResources(object))
+	if err != nil {
+		return errors.NewError(err, http.StatusBadRequest)
+	}
+
+	if err = c.factory.Cluster().Update(ctx, cid, *req.ResourceVersion, map[string]interface{}{
+		"kube_config": req.KubeConfig,
+	}); err != nil {
+		cs.Informer.Stop()
+		klog.Errorf("failed to update kubeConfig of cluster(%d): %v", cid, err)
+		return errors.ErrServerInternal
+	}
+
+	clusterIndexer.Replace(object.Name, *cs)
+	c.setStatus(ctx, object, model.ClusterStatusRunning, "集群 kubeConfig 已更新")
+	return nil
+}
+
+// Delete 标记集群为已删除并停止集群缓存，集群在保留期内可以恢复，超过保留期后由后台任务清理
+func (c *cluster) Delete(ctx context.Context, cid int64) error {
 	cluster, err := c.preDelete(ctx, cid)
 	if err != nil {
 		return err
 	}
 
-	// 后续考虑集群添加权限问题，暂时先注释掉
-	var txFunc = func(cluster *model.Cluster) (err error) {
-		//_, err = c.enforcer.RemoveNamedPolicy("p", user.Name, model.ObjectCluster.String(), cluster.GetSID())
-		return
-	}
-	if err = c.factory.Cluster().Delete(ctx, cluster, txFunc); err != nil {
+	if err = c.factory.Cluster().Delete(ctx, cluster); err != nil {
 		klog.Errorf("failed to delete cluster(%d): %v", cid, err)
 		return errors.ErrServerInternal
 	}
 
-	// 从缓存中移除 clusterSet
+	// 从缓存中移除 clusterSet，并停止 informer
 	clusterIndexer.Delete(cluster.Name)
 	return nil
 }
 
+func (c *cluster) Restore(ctx context.Context, cid int64) error {
+	object, err := c.factory.Cluster().GetDeleted(ctx, cid)
+	if err != nil {
+		klog.Errorf("failed to get deleted cluster(%d): %v", cid, err)
+		return errors.ErrServerInternal
+	}
+	if object == nil {
+		return errors.ErrClusterNotFound
+	}
+	if time.Since(object.GmtDeleted.Time) > c.cc.Job.ClusterRetention() {
+		return errors.NewError(fmt.Errorf("集群 %s 已超过保留期，等待清理，无法恢复", object.Name), http.StatusGone)
+	}
+
+	if err = c.factory.Cluster().Restore(ctx, cid); err != nil {
+		if utilerrors.IsNotUpdated(err) {
+			return errors.ErrClusterNotFound
+		}
+		klog.Errorf("failed to restore cluster(%d): %v", cid, err)
+		return errors.ErrServerInternal
+	}
+
+	// 请求结束后继续在后台构建集群缓存
+	go c.buildClusterSet(context.Background(), object)
+	return nil
+}
+
+func (c *cluster) ListDeleted(ctx context.Context, listOptions *types.ListOptions) (*types.PageResponse, error) {
+	objects, total, err := c.factory.Cluster().ListDeleted(ctx, listOptions.BuildPageNation()...)
+	if err != nil {
+		klog.Errorf("failed to list deleted clusters: %v", err)
+		return nil, errors.ErrServerInternal
+	}
+
+	cs := make([]types.Cluster, len(objects))
+	for i, object := range objects {
+		cs[i] = *c.model2Type(&object)
+	}
+
+	return &types.PageResponse{
+		Total:       int(total),
+		Items:       cs,
+		PageRequest: listOptions.PageRequest,
+	}, nil
+}
+
 func (c *cluster) Get(ctx context.Context, cid int64) (*types.Cluster, error) {
 	object, err := c.factory.Cluster().Get(ctx, cid)
 	if err != nil {
@@ -158,11 +315,43 @@ func (c *cluster) Get(ctx context.Context, cid int64) (*types.Cluster, error) {
 		return nil, errors.ErrClusterNotFound
 	}
 
-	return c.model2Type(object), nil
+	t := c.model2Type(object)
+	t.Resources = c.resources(ctx, object.Name)
+	return t, nil
+}
+
+// resources 获取集群 cpu 和 memory 的使用量和可分配总量，列表接口不返回，避免逐个请求 metrics-server
+// 仅在 pod 缓存已经启动时统计，避免为了集群详情加载整个集群的 pod
+func (c *cluster) resources(ctx context.Context, name string) types.Resources {
+	cs, ok := clusterIndexer.Get(name)
+	if !ok || !cs.Informer.HasStarted(client.PodsResource) {
+		return types.Resources{}
+	}
+	syncCtx, cancel := context.WithTimeout(ctx, resourcesSyncTimeout)
+	defer cancel()
+	if err := cs.Informer.Sync(syncCtx, client.NodesResource, client.PodsResource); err != nil {
+		return types.Resources{}
+	}
+	summary, err := cs.ClusterResources(ctx)
+	if err != nil || !summary.MetricsAvailable {
+		return types.Resources{}
+	}
+
+	cpuUsed, cpuCapacity := util.MultiCpuConvert(summary.Used.Cpu, summary.Capacity.Cpu)
+	memoryUsed, memoryCapacity := util.MultiSizeConvert(summary.Used.Memory, summary.Capacity.Memory)
+	return types.Resources{
+		Cpu:    cpuUsed + " / " + cpuCapacity,
+		Memory: memoryUsed + " / " + memoryCapacity,
+	}
 }
 
 func (c *cluster) List(ctx context.Context, listOptions *types.ListOptions) (*types.PageResponse, error) {
-	opts := append(ctrlutil.MakeDbOptions(ctx), listOptions.BuildPageNation()...)
+	query, err := listOptions.BuildQuery()
+	if err != nil {
+		return nil, errors.NewError(fmt.Errorf("标签选择器不合法: %v", err), http.StatusBadRequest)
+	}
+	opts := append(ctrlutil.MakeDbOptions(ctx), query...)
+	opts = append(opts, listOptions.BuildPageNation()...)
 
 	objects, total, err := c.factory.Cluster().List(ctx, opts...)
 	if err != nil {
@@ -181,12 +370,76 @@ func (c *cluster) List(ctx context.Context, listOptions *types.ListOptions) (*ty
 	}, nil
 }
 
-func (c *cluster) preCreate(ctx context.Context, req *types.CreateClusterRequest) error {
+// ListStatusRecords 获取集群状态变更记录，用于展示集群何时失联或恢复
+func (c *cluster) ListStatusRecords(ctx context.Context, cid int64, listOptions *types.ListOptions) (*types.PageResponse, error) {
+	objects, total, err := c.factory.Cluster().ListStatusRecords(ctx, cid, listOptions.BuildPageNation()...)
+	if err != nil {
+		klog.Errorf("failed to list status records of cluster(%d): %v", cid, err)
+		return nil, errors.ErrServerInternal
+	}
+
+	records := make([]types.ClusterStatusRecord, len(objects))
+	for i, object := range objects {
+		records[i] = types.ClusterStatusRecord{
+			Id:        object.Id,
+			ClusterId: object.ClusterId,
+			From:      object.From,
+			To:        object.To,
+			Message:   object.Message,
+			GmtCreate: object.GmtCreate,
+		}
+	}
+
+	return &types.PageResponse{
+		Total:       int(total),
+		Items:       records,
+		PageRequest: listOptions.PageRequest,
+	}, nil
+}
+
+// validateName 检查集群名称，集群名称会出现在 api 路径中，只允许英文、数字和中划线，不能为纯数字，且不能重复
+func (c *cluster) validateName(ctx context.Context, name string) error {
+	if len(name) > maxClusterNameLength || !clusterNameRegexp.MatchString(name) {
+		return errors.NewError(fmt.Errorf("集群名称 %q 不合法，只允许英文、数字和中划线，且不能以中划线开头或结尾，最长 %d 个字符", name, maxClusterNameLength), http.StatusBadRequest)
+	}
+	// 集群 id 和名称共用 api 路径中的 :cluster 段，纯数字的名称会和集群 id 混淆
+	if _, err := strconv.ParseInt(name, 10, 64); err == nil {
+		return errors.NewError(fmt.Errorf("集群名称 %q 不合法，不能为纯数字", name), http.StatusBadRequest)
+	}
+
+	_, err := c.factory.Cluster().GetByName(ctx, name)
+	if err == nil {
+		return errors.ErrClusterExists
+	}
+	if !utilerrors.IsRecordNotFound(err) {
+		klog.Errorf("failed to get cluster(%s): %v", name, err)
+		return errors.ErrServerInternal
+	}
+
+	// 已删除集群的名称在清理前保留，避免恢复时冲突，以及清理时误删同名集群的权限策略
+	_, total, err := c.factory.Cluster().ListDeleted(ctx, db.WithName(name))
+	if err != nil {
+		klog.Errorf("failed to list deleted cluster(%s): %v", name, err)
+		return errors.ErrServerInternal
+	}
+	if total != 0 {
+		return errors.NewError(fmt.Errorf("集群 %s 已删除但尚未清理，可以恢复该集群或者等待清理后重新创建", name), http.StatusConflict)
+	}
+	return nil
+}
+
+func (c *cluster) preCreate(ctx context.Context, req *types.CreateClusterRequest, resources []schema.GroupVersionResource) error {
 	// 实际创建前，先创建集群的连通性
 	if err := c.Ping(ctx, req.KubeConfig); err != nil {
 		return fmt.Errorf("尝试连接 kubernetes API 失败: %v", err)
 	}
-	return nil
+
+	// 集群不支持的资源会导致 informer 无法同步
+	clientSet, err := client.NewClientSetFromString(req.KubeConfig)
+	if err != nil {
+		return err
+	}
+	return client.ValidateResources(clientSet.Discovery(), resources)
 }
 
 // 删除前置检查
@@ -209,7 +462,20 @@ func (c *cluster) preDelete(ctx context.Context, cid int64) (cluster *model.Clus
 }
 
 func (c *cluster) model2Type(o *model.Cluster) *types.Cluster {
-	return &types.Cluster{
+	var nodes types.KubeNode
+	if len(o.Nodes) != 0 {
+		if err := nodes.Unmarshal(o.Nodes); err != nil {
+			klog.Warningf("failed to unmarshal nodes of cluster(%d): %v", o.Id, err)
+		}
+	}
+	var clusterLabels map[string]string
+	if len(o.Labels) != 0 {
+		if err := json.Unmarshal([]byte(o.Labels), &clusterLabels); err != nil {
+			klog.Warningf("failed to unmarshal labels of cluster(%d): %v", o.Id, err)
+		}
+	}
+
+	t := &types.Cluster{
 		VulpesMeta: types.VulpesMeta{
 			Id:              o.Id,
 			ResourceVersion: o.ResourceVersion,
@@ -221,10 +487,60 @@ func (c *cluster) model2Type(o *model.Cluster) *types.Cluster {
 		Name:              o.Name,
 		AliasName:         o.AliasName,
 		KubernetesVersion: o.KubernetesVersion,
+		Nodes:             nodes,
 		Status:            o.ClusterStatus, // 默认是运行中状态
 		Protected:         o.Protected,
 		Description:       o.Description,
+		Labels:            clusterLabels,
+		InformerResources: client.FormatResources(informerResources(o)),
+		PlanId:            o.PlanId,
+	}
+	if o.GmtDeleted.Valid {
+		deleted := o.GmtDeleted.Time
+		purge := deleted.Add(c.cc.Job.ClusterRetention())
+		t.GmtDeleted, t.GmtPurge = &deleted, &purge
+	}
+	return t
+}
+
+// resolveKubeConfig 返回检查后的 kubeConfig，使用凭证时将凭证转换为 kubeConfig
+func (c *cluster) resolveKubeConfig(kubeConfig string, credential *types.ClusterCredential) (string, error) {
+	if credential != nil {
+		var err error
+		if kubeConfig, err = credentialKubeConfig(credential); err != nil {
+			return "", err
+		}
+	}
+	return client.SanitizeKubeConfig(kubeConfig, c.kubeConfigPolicy())
+}
+
+// kubeConfigPolicy 返回配置的 kubeConfig 检查策略
+func (c *cluster) kubeConfigPolicy() client.KubeConfigPolicy {
+	return client.KubeConfigPolicy{
+		AllowedExecCommands:  c.cc.KubeConfig.AllowedExecCommands,
+		AllowedAuthProviders: c.cc.KubeConfig.AllowedAuthProviders,
+		AllowLocalFiles:      c.cc.KubeConfig.AllowLocalFiles,
+	}
+}
+
+// credentialKubeConfig 将请求中的凭证转换为 kubeConfig
+func credentialKubeConfig(credential *types.ClusterCredential) (string, error) {
+	cred := &client.Credential{
+		Server:                credential.Server,
+		InsecureSkipTLSVerify: credential.InsecureSkipTLSVerify,
+		Token:                 credential.Token,
+	}
+	var err error
+	if cred.CertificateAuthority, err = client.DecodePEM(credential.CertificateAuthority); err != nil {
+		return "", fmt.Errorf("certificate_authority 不合法: %v", err)
+	}
+	if cred.ClientCertificate, err = client.DecodePEM(credential.ClientCertificate); err != nil {
+		return "", fmt.Errorf("client_certificate 不合法: %v", err)
+	}
+	if cred.ClientKey, err = client.DecodePEM(credential.ClientKey); err != nil {
+		return "", fmt.Errorf("client_key 不合法: %v", err)
 	}
+	return cred.KubeConfig()
 }
 
 // Ping 检查和 k8s 集群的连通性
@@ -0,0 +1,129 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"kubevulpes/api/errors"
+	"kubevulpes/pkg/client"
+	"kubevulpes/pkg/types"
+)
+
+// PreviewKubeConfig 列出 kubeConfig 中的全部 context，并标记不允许导入的 context
+func (c *cluster) PreviewKubeConfig(ctx context.Context, req *types.PreviewKubeConfigRequest) ([]types.KubeConfigContext, error) {
+	config, err := loadKubeConfig(req.KubeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := c.kubeConfigPolicy()
+	items := make([]types.KubeConfigContext, 0, len(config.Contexts))
+	for name, kubeContext := range config.Contexts {
+		item := types.KubeConfigContext{
+			Name:      name,
+			Cluster:   kubeContext.Cluster,
+			User:      kubeContext.AuthInfo,
+			Namespace: kubeContext.Namespace,
+			Current:   name == config.CurrentContext,
+		}
+		if cluster, ok := config.Clusters[kubeContext.Cluster]; ok {
+			item.Server = cluster.Server
+		}
+		if _, err = client.SanitizeContext(config, name, policy); err != nil {
+			item.Error = err.Error()
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+	return items, nil
+}
+
+// Import 为选中的每个 context 创建一个集群，单个 context 失败不影响其他 context
+// 每个 context 独立完成 kubeConfig 检查、名称校验和连通性检查，结果按照请求顺序返回
+func (c *cluster) Import(ctx context.Context, req *types.ImportClustersRequest) ([]types.ImportClusterResult, error) {
+	config, err := loadKubeConfig(req.KubeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]types.ImportClusterResult, len(req.Contexts))
+	for i, item := range req.Contexts {
+		results[i] = types.ImportClusterResult{Context: item.Context}
+
+		kubeConfig, err := contextKubeConfig(config, item.Context, c.kubeConfigPolicy())
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		createReq := &types.CreateClusterRequest{
+			Name:        item.Name,
+			AliasName:   item.AliasName,
+			Description: item.Description,
+			Protected:   item.Protected,
+			KubeConfig:  kubeConfig,
+		}
+		if len(createReq.AliasName) == 0 {
+			createReq.AliasName = item.Context
+		}
+		if err = c.Create(ctx, createReq); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].Name = createReq.Name
+		results[i].Success = true
+	}
+	return results, nil
+}
+
+// loadKubeConfig 解析 base64 编码的 kubeConfig
+func loadKubeConfig(kubeConfig string) (*clientcmdapi.Config, error) {
+	data, err := client.ParseKubeConfigBytes(kubeConfig)
+	if err != nil {
+		return nil, errors.NewError(fmt.Errorf("kubeConfig 不是合法的 base64 编码: %v", err), http.StatusBadRequest)
+	}
+	config, err := clientcmd.Load(data)
+	if err != nil {
+		return nil, errors.NewError(fmt.Errorf("kubeConfig 解析失败: %v", err), http.StatusBadRequest)
+	}
+	if len(config.Contexts) == 0 {
+		return nil, errors.NewError(fmt.Errorf("kubeConfig 中没有 context"), http.StatusBadRequest)
+	}
+	return config, nil
+}
+
+// contextKubeConfig 返回只包含指定 context 的 base64 编码的 kubeConfig
+func contextKubeConfig(config *clientcmdapi.Config, contextName string, policy client.KubeConfigPolicy) (string, error) {
+	sanitized, err := client.SanitizeContext(config, contextName, policy)
+	if err != nil {
+		return "", err
+	}
+	data, err := clientcmd.Write(*sanitized)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
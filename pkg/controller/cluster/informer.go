@@ -0,0 +1,141 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	"kubevulpes/api/errors"
+	"kubevulpes/pkg/client"
+	"kubevulpes/pkg/db/model"
+	"kubevulpes/pkg/types"
+)
+
+// GetInformers 返回集群缓存的资源及其运行和同步状态，不会触发 informer 启动
+// 集群缓存尚未构建完成时，返回配置的资源，状态均为 false
+func (c *cluster) GetInformers(ctx context.Context, cid int64) ([]types.ClusterInformer, error) {
+	object, err := c.factory.Cluster().Get(ctx, cid)
+	if err != nil {
+		klog.Errorf("failed to get cluster(%d): %v", cid, err)
+		return nil, errors.ErrServerInternal
+	}
+	if object == nil {
+		return nil, errors.ErrClusterNotFound
+	}
+
+	cs, ok := clusterIndexer.Get(object.Name)
+	if !ok {
+		items := make([]types.ClusterInformer, 0)
+		for _, resource := range client.FormatResources(informerResources(object)) {
+			gvr, _ := client.ParseResource(resource)
+			items = append(items, types.ClusterInformer{
+				Resource: resource,
+				Required: client.IsRequiredResource(gvr),
+			})
+		}
+		return items, nil
+	}
+
+	statuses := cs.Informer.Resources()
+	items := make([]types.ClusterInformer, len(statuses))
+	for i, status := range statuses {
+		items[i] = types.ClusterInformer{
+			Resource: client.FormatResource(status.Resource),
+			Required: client.IsRequiredResource(status.Resource),
+			Started:  status.Started,
+			Synced:   status.Synced,
+		}
+	}
+	return items, nil
+}
+
+// UpdateInformers 调整集群缓存的资源，已构建缓存的集群立即生效，无需重建 clusterSet
+// 新增的资源在第一次访问时开始同步
+// 资源列表为空时恢复为默认资源
+func (c *cluster) UpdateInformers(ctx context.Context, cid int64, req *types.UpdateClusterInformersRequest) error {
+	object, err := c.factory.Cluster().Get(ctx, cid)
+	if err != nil {
+		klog.Errorf("failed to get cluster(%d): %v", cid, err)
+		return errors.ErrServerInternal
+	}
+	if object == nil {
+		return errors.ErrClusterNotFound
+	}
+
+	resources, err := client.ParseResources(req.Resources)
+	if err != nil {
+		return errors.NewError(err, http.StatusBadRequest)
+	}
+	cs, ok := clusterIndexer.Get(object.Name)
+	if ok {
+		if err = client.ValidateResources(cs.Client.Discovery(), resources); err != nil {
+			return errors.NewError(fmt.Errorf("缓存资源不合法: %v", err), http.StatusBadRequest)
+		}
+	}
+
+	if err = c.factory.Cluster().Update(ctx, cid, *req.ResourceVersion, map[string]interface{}{
+		"informer_resources": marshalInformerResources(req.Resources),
+	}); err != nil {
+		klog.Errorf("failed to update informer resources of cluster(%d): %v", cid, err)
+		return errors.ErrServerInternal
+	}
+
+	// 集群缓存构建中时，构建完成后会使用数据库中最新的配置
+	if ok {
+		cs.Informer.SetResources(resources)
+	}
+	return nil
+}
+
+// informerResources 解析集群配置的缓存资源，未配置或者解析失败时使用默认资源
+func informerResources(object *model.Cluster) []schema.GroupVersionResource {
+	if len(object.InformerResources) == 0 {
+		return client.DefaultResources()
+	}
+
+	var items []string
+	if err := json.Unmarshal([]byte(object.InformerResources), &items); err != nil {
+		klog.Warningf("failed to unmarshal informer resources of cluster(%d): %v", object.Id, err)
+		return client.DefaultResources()
+	}
+	resources, err := client.ParseResources(items)
+	if err != nil {
+		klog.Warningf("invalid informer resources of cluster(%d): %v", object.Id, err)
+		return client.DefaultResources()
+	}
+	return resources
+}
+
+// marshalInformerResources 序列化缓存资源，为空时存储空字符串，跟随默认资源
+func marshalInformerResources(items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+
+	resources, err := client.ParseResources(items)
+	if err != nil {
+		return ""
+	}
+	data, _ := json.Marshal(client.FormatResources(resources))
+	return string(data)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"path"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+
+	"kubevulpes/api/errors"
+	"kubevulpes/api/httputils"
+)
+
+// Proxy 返回将请求转发至集群 apiserver 的 handler
+// 请求以当前用户的身份扮演，由按照用户在 vulpes 中的权限同步的 RBAC 鉴权，apiPath 为 apiserver 的请求路径，例如 /api/v1/pods
+// 导入集群使用的凭证需要拥有 users 的 impersonate 权限
+func (c *cluster) Proxy(ctx context.Context, cid int64, apiPath string) (http.Handler, error) {
+	user, err := httputils.GetUserFromRequest(ctx)
+	if err != nil {
+		return nil, errors.NewError(err, http.StatusUnauthorized)
+	}
+	object, err := c.factory.Cluster().Get(ctx, cid)
+	if err != nil {
+		klog.Errorf("failed to get cluster(%d): %v", cid, err)
+		return nil, errors.ErrServerInternal
+	}
+	if object == nil {
+		return nil, errors.ErrClusterNotFound
+	}
+	cs, ok := clusterIndexer.Get(object.Name)
+	if !ok {
+		return nil, errors.NewError(fmt.Errorf("集群 %s 未就绪，请稍后重试", object.Name), http.StatusServiceUnavailable)
+	}
+
+	// 用户组的策略已经合并到用户的授权中，只扮演用户
+	if err = c.ensureUserAccess(ctx, cs, object, user); err != nil {
+		return nil, err
+	}
+	config := restclient.CopyConfig(cs.Config)
+	config.Impersonate = restclient.ImpersonationConfig{UserName: impersonateUser(user.Name)}
+	transport, err := restclient.TransportFor(config)
+	if err != nil {
+		klog.Errorf("failed to build transport of cluster(%s): %v", object.Name, err)
+		return nil, errors.ErrServerInternal
+	}
+	target, _, err := restclient.DefaultServerURL(config.Host, "", schema.GroupVersion{}, restclient.IsConfigTransportTLS(*config))
+	if err != nil {
+		klog.Errorf("failed to parse server of cluster(%s): %v", object.Name, err)
+		return nil, errors.ErrServerInternal
+	}
+
+	return &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = target.Scheme
+			req.URL.Host = target.Host
+			req.URL.Path = path.Join("/", target.Path, apiPath)
+			req.URL.RawPath = ""
+			req.Host = target.Host
+			// vulpes 的 token 不能转发至集群，同时禁止客户端自行指定扮演的身份
+			req.Header.Del("Authorization")
+			for key := range req.Header {
+				if strings.HasPrefix(key, "Impersonate-") {
+					req.Header.Del(key)
+				}
+			}
+		},
+		Transport: transport,
+		// watch 和日志等流式响应需要立即写回客户端
+		FlushInterval: -1,
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			klog.Errorf("failed to proxy %s %s to cluster(%s): %v", req.Method, apiPath, object.Name, err)
+			http.Error(w, err.Error(), http.StatusBadGateway)
+		},
+	}, nil
+}
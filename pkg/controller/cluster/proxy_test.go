@@ -0,0 +1,110 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	restclient "k8s.io/client-go/rest"
+
+	"kubevulpes/api/httputils"
+	"kubevulpes/pkg/client"
+	"kubevulpes/pkg/db"
+	"kubevulpes/pkg/db/model"
+)
+
+// fakeFactory 只实现代理用到的 Cluster 接口
+type fakeFactory struct {
+	db.ShareDaoFactory
+	cluster *fakeClusterDao
+}
+
+func (f *fakeFactory) Cluster() db.ClusterInterface { return f.cluster }
+
+type fakeClusterDao struct {
+	db.ClusterInterface
+	object *model.Cluster
+}
+
+func (f *fakeClusterDao) Get(ctx context.Context, cid int64, opts ...db.Options) (*model.Cluster, error) {
+	if f.object.Id != cid {
+		return nil, nil
+	}
+	return f.object, nil
+}
+
+func TestProxyHeaders(t *testing.T) {
+	var received http.Header
+	var receivedPath string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, receivedPath = r.Header.Clone(), r.URL.Path
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	object := &model.Cluster{Name: "proxy-test"}
+	object.Id = 1
+	user := &model.User{Name: "foo"}
+	user.Id = 2
+	clusterIndexer.Set(object.Name, client.ClusterSet{Config: &restclient.Config{Host: ts.URL, BearerToken: "cluster-token"}})
+	// 跳过集群内授权的同步，只验证转发的请求
+	reconciledAccess.Add(reconciledKey(user.Id, object.Id), time.Now())
+
+	c := &cluster{factory: &fakeFactory{cluster: &fakeClusterDao{object: object}}}
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	httputils.SetUserToContext(ctx, user)
+	handler, err := c.Proxy(ctx, object.Id, "/api/v1/namespaces/default/pods")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/clusters/1/proxy/api/v1/namespaces/default/pods", nil)
+	req.Header.Set("Authorization", "Bearer vulpes-token")
+	req.Header.Set("Impersonate-User", "admin")
+	req.Header.Set("Impersonate-Group", "system:masters")
+	req.Header.Set("Impersonate-Uid", "0")
+	req.Header.Set("Impersonate-Extra-Scopes", "all")
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	if receivedPath != "/api/v1/namespaces/default/pods" {
+		t.Errorf("unexpected path %s", receivedPath)
+	}
+	if got := received.Get("Authorization"); got != "Bearer cluster-token" {
+		t.Errorf("expected the cluster credential, got Authorization %q", got)
+	}
+	if got := received.Values("Impersonate-User"); len(got) != 1 || got[0] != impersonateUser(user.Name) {
+		t.Errorf("expected to impersonate %s, got %q", impersonateUser(user.Name), got)
+	}
+	for _, key := range []string{"Impersonate-Group", "Impersonate-Uid", "Impersonate-Extra-Scopes"} {
+		if got := received.Values(key); len(got) != 0 {
+			t.Errorf("expected %s to be removed, got %q", key, got)
+		}
+	}
+	if got := received.Get("Accept"); got != "application/json" {
+		t.Errorf("expected Accept to be kept, got %q", got)
+	}
+}
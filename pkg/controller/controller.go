@@ -23,6 +23,9 @@ import (
 	"kubevulpes/pkg/controller/audit"
 	"kubevulpes/pkg/controller/auth"
 	"kubevulpes/pkg/controller/cluster"
+	"kubevulpes/pkg/controller/host"
+	"kubevulpes/pkg/controller/kube"
+	"kubevulpes/pkg/controller/plan"
 	"kubevulpes/pkg/controller/user"
 	"kubevulpes/pkg/db"
 )
@@ -32,6 +35,9 @@ type VuplesInterface interface {
 	cluster.ClusterGetter
 	auth.AuthGetter
 	audit.AuditGetter
+	kube.KubeGetter
+	host.HostGetter
+	plan.PlanGetter
 }
 
 type vuples struct {
@@ -40,10 +46,13 @@ type vuples struct {
 	enforcer *casbin.SyncedEnforcer
 }
 
-func (p *vuples) User() user.Interface       { return user.NewUser(p.cc, p.factory, p.enforcer) }
+func (p *vuples) User() user.Interface       { return user.NewUser(p.cc, p.factory, p.enforcer, p.Cluster()) }
 func (p *vuples) Cluster() cluster.Interface { return cluster.NewCluster(p.cc, p.factory, p.enforcer) }
-func (p *vuples) Auth() auth.Interface       { return auth.NewAuth(p.factory, p.enforcer) }
+func (p *vuples) Auth() auth.Interface       { return auth.NewAuth(p.factory, p.enforcer, p.Cluster()) }
 func (p *vuples) Audit() audit.Interface     { return audit.NewAudit(p.cc, p.factory) }
+func (p *vuples) Kube() kube.Interface       { return kube.NewKube(p.cc, p.factory, p.enforcer) }
+func (p *vuples) Host() host.Interface       { return host.NewHost(p.cc, p.factory) }
+func (p *vuples) Plan() plan.Interface       { return plan.NewPlan(p.cc, p.factory) }
 
 func New(cfg config.Config, f db.ShareDaoFactory, e *casbin.SyncedEnforcer) VuplesInterface {
 	return &vuples{
@@ -0,0 +1,161 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package host
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"k8s.io/klog/v2"
+
+	"kubevulpes/api/errors"
+	"kubevulpes/pkg/db/model"
+	"kubevulpes/pkg/types"
+)
+
+const hostCheckTimeout = 30 * time.Second
+
+// factsScript 每行输出一项 key=value 格式的系统信息，未安装的运行时不输出
+const factsScript = `
+. /etc/os-release 2>/dev/null
+echo "os=${PRETTY_NAME:-$(uname -s)}"
+echo "kernel=$(uname -r)"
+echo "arch=$(uname -m)"
+echo "cpu=$(nproc 2>/dev/null || grep -c ^processor /proc/cpuinfo)"
+echo "memory=$(awk '/^MemTotal:/ {print $2}' /proc/meminfo)"
+if command -v containerd >/dev/null 2>&1; then
+  echo "runtime=containerd $(containerd --version | awk '{print $3}')"
+elif command -v docker >/dev/null 2>&1; then
+  echo "runtime=docker $(docker version --format '{{.Server.Version}}' 2>/dev/null)"
+fi
+`
+
+// Check 登陆失败时记录为离线状态并返回主机，不作为接口错误
+func (h *host) Check(ctx context.Context, hostId int64) (*types.Host, error) {
+	object, err := h.factory.Host().Get(ctx, hostId)
+	if err != nil {
+		klog.Errorf("failed to get host(%d): %v", hostId, err)
+		return nil, errors.ErrServerInternal
+	}
+	if object == nil {
+		return nil, errors.ErrHostNotFound
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, hostCheckTimeout)
+	defer cancel()
+
+	now := time.Now()
+	updates := map[string]interface{}{"last_check_time": now}
+	facts, hostKey, err := collectFacts(checkCtx, object)
+	if err != nil {
+		updates["status"] = model.HostStatusOffline
+		updates["message"] = err.Error()
+	} else {
+		// 第一次检查成功时记录 host key，之后的连接按照记录的 host key 校验
+		if len(object.HostKey) == 0 {
+			updates["host_key"] = string(ssh.MarshalAuthorizedKey(hostKey))
+		}
+		data, err := facts.Marshal()
+		if err != nil {
+			klog.Errorf("failed to marshal facts of host(%d): %v", hostId, err)
+			return nil, errors.ErrServerInternal
+		}
+		updates["status"] = model.HostStatusOnline
+		updates["message"] = ""
+		updates["facts"] = data
+	}
+
+	// 检查结果由系统维护，不递增 resourceVersion，避免和用户的修改冲突
+	if err = h.factory.Host().InternalUpdate(ctx, hostId, updates); err != nil {
+		klog.Errorf("failed to save check result of host(%d): %v", hostId, err)
+		return nil, errors.ErrServerInternal
+	}
+	return h.Get(ctx, hostId)
+}
+
+func collectFacts(ctx context.Context, object *model.Host) (*types.HostFacts, ssh.PublicKey, error) {
+	sshClient, hostKey, err := dial(object, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer sshClient.Close()
+
+	output, err := run(ctx, sshClient, factsScript)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to collect facts of host %s: %v", object.Name, err)
+	}
+	return parseFacts(output), hostKey, nil
+}
+
+// run 执行命令并返回标准输出，ctx 结束时关闭连接以中断命令
+func run(ctx context.Context, sshClient *ssh.Client, cmd string) (string, error) {
+	session, err := sshClient.NewSession()
+	if err != nil {
+		return "", err
+	}
+	defer session.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = sshClient.Close()
+		case <-done:
+		}
+	}()
+
+	output, err := session.Output(cmd)
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	return string(output), err
+}
+
+func parseFacts(output string) *types.HostFacts {
+	facts := &types.HostFacts{}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "os":
+			facts.OS = value
+		case "kernel":
+			facts.Kernel = value
+		case "arch":
+			facts.Arch = value
+		case "cpu":
+			facts.Cpu, _ = strconv.Atoi(value)
+		case "memory":
+			// /proc/meminfo 的单位为 kB
+			kb, _ := strconv.ParseInt(value, 10, 64)
+			facts.Memory = kb * 1024
+		case "runtime":
+			facts.ContainerRuntime = value
+		}
+	}
+	return facts
+}
@@ -0,0 +1,425 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package host
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/crypto/ssh"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	"kubevulpes/api/errors"
+	"kubevulpes/cmd/app/config"
+	ctrlutil "kubevulpes/pkg/controller/util"
+	"kubevulpes/pkg/db"
+	"kubevulpes/pkg/db/model"
+	"kubevulpes/pkg/types"
+	utilerrors "kubevulpes/pkg/util/errors"
+)
+
+const defaultSSHPort = 22
+
+type HostGetter interface {
+	Host() Interface
+}
+
+// SessionMeta SSH 会话的操作人和请求 ID，用于关联审计记录
+type SessionMeta struct {
+	Operator  string
+	RequestId string
+}
+
+type Interface interface {
+	Create(ctx context.Context, req *types.CreateHostRequest) (*types.Host, error)
+	Update(ctx context.Context, hostId int64, req *types.UpdateHostRequest) error
+	Delete(ctx context.Context, hostId int64) error
+	Get(ctx context.Context, hostId int64) (*types.Host, error)
+	List(ctx context.Context, listOptions *types.ListHostOptions) (*types.PageResponse, error)
+	// Check 登陆主机采集系统信息，结果保存到主机记录中
+	Check(ctx context.Context, hostId int64) (*types.Host, error)
+
+	SSH(ctx context.Context, hostId int64, conn *websocket.Conn, meta SessionMeta) error
+	GetSession(ctx context.Context, hostId int64, sessionId int64) (*types.HostSession, error)
+	ListSessions(ctx context.Context, hostId int64, listOptions *types.ListOptions) (*types.PageResponse, error)
+}
+
+type host struct {
+	cc      config.Config
+	factory db.ShareDaoFactory
+}
+
+func (h *host) Create(ctx context.Context, req *types.CreateHostRequest) (*types.Host, error) {
+	object := &model.Host{
+		Name:    req.Name,
+		Address: req.Address,
+		Port:    req.Port,
+		Role:    string(req.Role),
+		Status:  model.HostStatusUnknown,
+	}
+	if object.Port == 0 {
+		object.Port = defaultSSHPort
+	}
+	if len(object.Role) == 0 {
+		object.Role = string(types.RoleNode)
+	}
+
+	var err error
+	if object.Labels, err = ctrlutil.MarshalLabels(req.Labels); err != nil {
+		return nil, errors.NewError(err, http.StatusBadRequest)
+	}
+	if err = setCredential(object, &req.Auth); err != nil {
+		return nil, errors.NewError(err, http.StatusBadRequest)
+	}
+
+	if object, err = h.factory.Host().Create(ctx, object); err != nil {
+		if utilerrors.IsUniqueConstraintError(err) {
+			return nil, errors.ErrHostExists
+		}
+		klog.Errorf("failed to create host(%s): %v", req.Name, err)
+		return nil, errors.ErrServerInternal
+	}
+	return h.model2Type(object), nil
+}
+
+// Update 修改地址或者登陆凭证后，之前的检查结果不再可信，重置为未检查
+func (h *host) Update(ctx context.Context, hostId int64, req *types.UpdateHostRequest) error {
+	object, err := h.factory.Host().Get(ctx, hostId)
+	if err != nil {
+		klog.Errorf("failed to get host(%d): %v", hostId, err)
+		return errors.ErrServerInternal
+	}
+	if object == nil {
+		return errors.ErrHostNotFound
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Address != nil && *req.Address != object.Address {
+		updates["address"] = *req.Address
+	}
+	if req.Port != nil && *req.Port != object.Port {
+		updates["port"] = *req.Port
+	}
+	if req.Role != nil {
+		updates["role"] = string(*req.Role)
+	}
+	if req.Labels != nil {
+		if updates["labels"], err = ctrlutil.MarshalLabels(*req.Labels); err != nil {
+			return errors.NewError(err, http.StatusBadRequest)
+		}
+	}
+	if req.Auth != nil {
+		if err = setCredential(object, req.Auth); err != nil {
+			return errors.NewError(err, http.StatusBadRequest)
+		}
+		updates["auth_type"] = object.AuthType
+		updates["user"] = object.User
+		updates["password"] = object.Password
+		updates["private_key"] = object.PrivateKey
+	}
+	if len(updates) == 0 && !req.ResetHostKey {
+		return errors.ErrInvalidRequest
+	}
+	_, addressChanged := updates["address"]
+	_, portChanged := updates["port"]
+	if addressChanged || portChanged || req.Auth != nil {
+		updates["status"] = model.HostStatusUnknown
+		updates["message"] = ""
+	}
+	// 地址变化后是另一台机器，主机重装后需要重置，下次检查时重新记录 host key
+	if addressChanged || portChanged || req.ResetHostKey {
+		updates["host_key"] = ""
+	}
+
+	if err = h.factory.Host().Update(ctx, hostId, *req.ResourceVersion, updates); err != nil {
+		if utilerrors.IsUniqueConstraintError(err) {
+			return errors.ErrHostExists
+		}
+		if utilerrors.IsNotUpdated(err) {
+			return errors.NewError(fmt.Errorf("主机已被修改，请刷新后重试"), http.StatusConflict)
+		}
+		klog.Errorf("failed to update host(%d): %v", hostId, err)
+		return errors.ErrServerInternal
+	}
+	return nil
+}
+
+// Delete 被部署规划使用的主机不允许删除
+func (h *host) Delete(ctx context.Context, hostId int64) error {
+	object, err := h.factory.Host().Get(ctx, hostId)
+	if err != nil {
+		klog.Errorf("failed to get host(%d): %v", hostId, err)
+		return errors.ErrServerInternal
+	}
+	if object == nil {
+		return errors.ErrHostNotFound
+	}
+
+	plans, _, err := h.factory.Plan().List(ctx)
+	if err != nil {
+		klog.Errorf("failed to list plans: %v", err)
+		return errors.ErrServerInternal
+	}
+	for _, plan := range plans {
+		var nodes types.PlanNodes
+		if len(plan.Nodes) == 0 {
+			continue
+		}
+		if err = nodes.Unmarshal(plan.Nodes); err != nil {
+			klog.Warningf("failed to unmarshal nodes of plan(%d): %v", plan.Id, err)
+			continue
+		}
+		for _, node := range nodes {
+			if node.HostId == hostId {
+				return errors.NewError(fmt.Errorf("主机 %s 被部署规划 %s 使用，不允许删除", object.Name, plan.Name), http.StatusConflict)
+			}
+		}
+	}
+
+	if err = h.factory.Host().Delete(ctx, hostId); err != nil {
+		klog.Errorf("failed to delete host(%d): %v", hostId, err)
+		return errors.ErrServerInternal
+	}
+	return nil
+}
+
+func (h *host) Get(ctx context.Context, hostId int64) (*types.Host, error) {
+	object, err := h.factory.Host().Get(ctx, hostId)
+	if err != nil {
+		klog.Errorf("failed to get host(%d): %v", hostId, err)
+		return nil, errors.ErrServerInternal
+	}
+	if object == nil {
+		return nil, errors.ErrHostNotFound
+	}
+
+	return h.model2Type(object), nil
+}
+
+// List 按照角色或者标签过滤时，需要先取出全部主机再分页
+func (h *host) List(ctx context.Context, listOptions *types.ListHostOptions) (*types.PageResponse, error) {
+	selector, err := labels.Parse(listOptions.LabelSelector)
+	if err != nil {
+		return nil, errors.NewError(err, http.StatusBadRequest)
+	}
+
+	opts := listOptions.BuildPageNation()
+	filtered := !selector.Empty() || len(listOptions.Role) != 0
+	if filtered {
+		opts = []db.Options{db.WithOrderByASC()}
+		if listOptions.IsDesc() {
+			opts = []db.Options{db.WithOrderByDesc()}
+		}
+	}
+	objects, total, err := h.factory.Host().List(ctx, opts...)
+	if err != nil {
+		klog.Errorf("failed to list hosts: %v", err)
+		return nil, errors.ErrServerInternal
+	}
+
+	hosts := make([]types.Host, 0, len(objects))
+	for i := range objects {
+		t := h.model2Type(&objects[i])
+		if filtered && !matchHost(t, listOptions.Role, selector) {
+			continue
+		}
+		hosts = append(hosts, *t)
+	}
+	if filtered {
+		total = int64(len(hosts))
+		if listOptions.IsPaged() {
+			offset, end, err := listOptions.Offset(len(hosts))
+			if err != nil {
+				return nil, errors.NewError(err, http.StatusBadRequest)
+			}
+			hosts = hosts[offset:end]
+		}
+	}
+
+	return &types.PageResponse{
+		PageRequest: listOptions.PageRequest,
+		Total:       int(total),
+		Items:       hosts,
+	}, nil
+}
+
+func matchHost(t *types.Host, role types.NodeRole, selector labels.Selector) bool {
+	if len(role) != 0 && t.Role != role {
+		return false
+	}
+	return selector.Matches(labels.Set(t.Labels))
+}
+
+// SSH 将 websocket 连接桥接到主机的 ssh 终端，会话结束后保存用户的输入
+func (h *host) SSH(ctx context.Context, hostId int64, conn *websocket.Conn, meta SessionMeta) error {
+	object, err := h.factory.Host().Get(ctx, hostId)
+	if err != nil {
+		klog.Errorf("failed to get host(%d): %v", hostId, err)
+		return errors.ErrServerInternal
+	}
+	if object == nil {
+		return errors.ErrHostNotFound
+	}
+
+	sshClient, _, err := dial(object, false)
+	if err != nil {
+		return err
+	}
+	defer sshClient.Close()
+
+	turn, err := types.NewTurn(conn, sshClient)
+	if err != nil {
+		return err
+	}
+
+	startTime := time.Now()
+	var (
+		wg      sync.WaitGroup
+		logBuff bytes.Buffer
+	)
+	sessionCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// 任意一端结束时关闭 turn，使另一端随之退出
+	wg.Add(2)
+	go func() {
+		turn.StartLoopRead(sessionCtx, &wg, &logBuff)
+		_ = turn.Close()
+	}()
+	go func() {
+		turn.StartSessionWait(&wg)
+		_ = turn.Close()
+	}()
+	wg.Wait()
+
+	session := &model.HostSession{
+		HostId:    hostId,
+		RequestId: meta.RequestId,
+		Operator:  meta.Operator,
+		StartTime: startTime,
+		EndTime:   time.Now(),
+		Input:     logBuff.String(),
+	}
+	if err = h.factory.Host().CreateSession(context.TODO(), session); err != nil {
+		klog.Errorf("failed to save ssh session of host(%d) by %s: %v", hostId, meta.Operator, err)
+	}
+	return nil
+}
+
+func (h *host) GetSession(ctx context.Context, hostId int64, sessionId int64) (*types.HostSession, error) {
+	object, err := h.factory.Host().GetSession(ctx, hostId, sessionId)
+	if err != nil {
+		klog.Errorf("failed to get session %d of host(%d): %v", sessionId, hostId, err)
+		return nil, errors.ErrServerInternal
+	}
+	if object == nil {
+		return nil, errors.ErrHostSessionNotFound
+	}
+
+	return h.session2Type(object), nil
+}
+
+func (h *host) ListSessions(ctx context.Context, hostId int64, listOptions *types.ListOptions) (*types.PageResponse, error) {
+	objects, total, err := h.factory.Host().ListSessions(ctx, hostId, listOptions.BuildPageNation()...)
+	if err != nil {
+		klog.Errorf("failed to list sessions of host(%d): %v", hostId, err)
+		return nil, errors.ErrServerInternal
+	}
+
+	sessions := make([]types.HostSession, len(objects))
+	for i := range objects {
+		sessions[i] = *h.session2Type(&objects[i])
+	}
+	return &types.PageResponse{
+		PageRequest: listOptions.PageRequest,
+		Total:       int(total),
+		Items:       sessions,
+	}, nil
+}
+
+func (h *host) session2Type(o *model.HostSession) *types.HostSession {
+	return &types.HostSession{
+		Id:        o.Id,
+		HostId:    o.HostId,
+		RequestId: o.RequestId,
+		Operator:  o.Operator,
+		StartTime: o.StartTime,
+		EndTime:   o.EndTime,
+		Input:     o.Input,
+	}
+}
+
+func (h *host) model2Type(o *model.Host) *types.Host {
+	t := &types.Host{
+		VulpesMeta: types.VulpesMeta{
+			Id:              o.Id,
+			ResourceVersion: o.ResourceVersion,
+		},
+		TimeMeta: types.TimeMeta{
+			GmtCreate:   o.GmtCreate,
+			GmtModified: o.GmtModified,
+		},
+		Name:          o.Name,
+		Address:       o.Address,
+		Port:          o.Port,
+		AuthType:      types.AuthType(o.AuthType),
+		User:          o.User,
+		Role:          types.NodeRole(o.Role),
+		Status:        o.Status.String(),
+		Message:       o.Message,
+		LastCheckTime: o.LastCheckTime,
+	}
+	if len(o.HostKey) != 0 {
+		if key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(o.HostKey)); err == nil {
+			t.HostKeyFingerprint = ssh.FingerprintSHA256(key)
+		}
+	}
+	if len(o.Labels) != 0 {
+		if err := json.Unmarshal([]byte(o.Labels), &t.Labels); err != nil {
+			klog.Warningf("failed to unmarshal labels of host(%d): %v", o.Id, err)
+		}
+	}
+	if len(o.Facts) != 0 {
+		if err := t.Facts.Unmarshal(o.Facts); err != nil {
+			klog.Warningf("failed to unmarshal facts of host(%d): %v", o.Id, err)
+		}
+	}
+	return t
+}
+
+func address(object *model.Host) string {
+	return net.JoinHostPort(object.Address, strconv.Itoa(object.Port))
+}
+
+func NewHost(cfg config.Config, f db.ShareDaoFactory) *host {
+	return &host{
+		cc:      cfg,
+		factory: f,
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package host
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"kubevulpes/api/errors"
+	"kubevulpes/pkg/db/model"
+	"kubevulpes/pkg/types"
+)
+
+const sshDialTimeout = 10 * time.Second
+
+// hostKeyVerifier 首次检查主机时信任并记录主机的 host key（TOFU），之后的连接必须和记录的 host key 一致
+// host key 在认证之前校验，不一致时不会发送主机的登陆凭证
+type hostKeyVerifier struct {
+	name  string
+	known ssh.PublicKey
+	// 是否允许信任未记录的 host key，只有检查主机时允许
+	trustOnFirstUse bool
+
+	// 本次连接中主机提供的 host key
+	key ssh.PublicKey
+}
+
+func newHostKeyVerifier(object *model.Host, trustOnFirstUse bool) (*hostKeyVerifier, error) {
+	v := &hostKeyVerifier{name: object.Name, trustOnFirstUse: trustOnFirstUse}
+	if len(object.HostKey) != 0 {
+		known, _, _, _, err := ssh.ParseAuthorizedKey([]byte(object.HostKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse recorded host key of host %s: %v", object.Name, err)
+		}
+		v.known = known
+	}
+	return v, nil
+}
+
+func (v *hostKeyVerifier) verify(_ string, _ net.Addr, key ssh.PublicKey) error {
+	if v.known == nil {
+		if !v.trustOnFirstUse {
+			return fmt.Errorf("主机 %s 的 host key 尚未记录，请先检查主机", v.name)
+		}
+	} else if !bytes.Equal(v.known.Marshal(), key.Marshal()) {
+		return fmt.Errorf("主机 %s 的 host key 与记录的不一致，记录的指纹为 %s，实际为 %s", v.name, ssh.FingerprintSHA256(v.known), ssh.FingerprintSHA256(key))
+	}
+	v.key = key
+	return nil
+}
+
+// dial 使用主机保存的认证信息建立 ssh 连接，返回主机的 host key
+func dial(object *model.Host, trustOnFirstUse bool) (*ssh.Client, ssh.PublicKey, error) {
+	auth, err := authMethod(object)
+	if err != nil {
+		return nil, nil, errors.NewError(err, http.StatusBadRequest)
+	}
+	verifier, err := newHostKeyVerifier(object, trustOnFirstUse)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := ssh.Dial("tcp", address(object), &ssh.ClientConfig{
+		User:            object.User,
+		Auth:            []ssh.AuthMethod{auth},
+		HostKeyCallback: verifier.verify,
+		Timeout:         sshDialTimeout,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to host %s(%s): %v", object.Name, address(object), err)
+	}
+	return client, verifier.key, nil
+}
+
+func authMethod(object *model.Host) (ssh.AuthMethod, error) {
+	switch types.AuthType(object.AuthType) {
+	case types.PasswordAuth:
+		return ssh.Password(object.Password), nil
+	case types.KeyAuth:
+		signer, err := ssh.ParsePrivateKey([]byte(object.PrivateKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key of host %s: %v", object.Name, err)
+		}
+		return ssh.PublicKeys(signer), nil
+	default:
+		return nil, fmt.Errorf("unsupported auth type %q of host %s", object.AuthType, object.Name)
+	}
+}
+
+// setCredential 根据请求设置主机的登陆凭证，切换认证方式时清空另一种凭证
+func setCredential(object *model.Host, auth *types.HostAuth) error {
+	switch auth.Type {
+	case types.PasswordAuth:
+		if auth.Password == nil || len(auth.Password.User) == 0 || len(auth.Password.Password) == 0 {
+			return fmt.Errorf("密码认证需要填写用户名和密码")
+		}
+		object.User, object.Password, object.PrivateKey = auth.Password.User, auth.Password.Password, ""
+	case types.KeyAuth:
+		if auth.Key == nil || len(auth.Key.User) == 0 || len(auth.Key.Data) == 0 {
+			return fmt.Errorf("私钥认证需要填写用户名和私钥")
+		}
+		if _, err := ssh.ParsePrivateKey([]byte(auth.Key.Data)); err != nil {
+			return fmt.Errorf("私钥格式不正确: %v", err)
+		}
+		object.User, object.Password, object.PrivateKey = auth.Key.User, "", auth.Key.Data
+	default:
+		return fmt.Errorf("不支持的认证方式 %q", auth.Type)
+	}
+
+	object.AuthType = string(auth.Type)
+	return nil
+}
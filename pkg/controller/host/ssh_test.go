@@ -0,0 +1,493 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package host
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/crypto/ssh"
+
+	"kubevulpes/cmd/app/config"
+	"kubevulpes/pkg/db"
+	"kubevulpes/pkg/db/model"
+	"kubevulpes/pkg/types"
+)
+
+const (
+	testUser     = "vulpes"
+	testPassword = "secret"
+)
+
+// fakeFactory 只实现 ssh 终端用到的 Host 接口
+type fakeFactory struct {
+	db.ShareDaoFactory
+	host *fakeHostDao
+}
+
+func (f *fakeFactory) Host() db.HostInterface { return f.host }
+
+type fakeHostDao struct {
+	db.HostInterface
+	object *model.Host
+
+	lock     sync.Mutex
+	sessions []*model.HostSession
+	updates  []map[string]interface{}
+}
+
+func (f *fakeHostDao) Get(_ context.Context, hostId int64) (*model.Host, error) {
+	if hostId != f.object.Id {
+		return nil, nil
+	}
+	return f.object, nil
+}
+
+func (f *fakeHostDao) InternalUpdate(_ context.Context, _ int64, updates map[string]interface{}) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.updates = append(f.updates, updates)
+	return nil
+}
+
+func (f *fakeHostDao) CreateSession(_ context.Context, object *model.HostSession) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.sessions = append(f.sessions, object)
+	return nil
+}
+
+// testSSHServer 回显 shell 的输入，收到 exit 时退出，同时记录窗口大小的变化和认证的次数
+// exec 请求固定返回 testFacts
+type testSSHServer struct {
+	listener net.Listener
+	config   *ssh.ServerConfig
+	hostKey  ssh.PublicKey
+
+	lock         sync.Mutex
+	resizes      [][2]uint32
+	authAttempts int
+}
+
+const testFacts = "os=Test Linux\nkernel=6.1.0\ncpu=4\n"
+
+func newTestSSHServer(t *testing.T, authorizedKey ssh.PublicKey) *testSSHServer {
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &testSSHServer{hostKey: signer.PublicKey()}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			s.recordAuth()
+			if conn.User() == testUser && string(password) == testPassword {
+				return nil, nil
+			}
+			return nil, ssh.ErrNoAuth
+		},
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			s.recordAuth()
+			if authorizedKey != nil && conn.User() == testUser && bytes.Equal(key.Marshal(), authorizedKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, ssh.ErrNoAuth
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.listener, s.config = listener, config
+	t.Cleanup(func() { _ = listener.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *testSSHServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *testSSHServer) handleConn(conn net.Conn) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go s.handleSession(channel, requests)
+	}
+}
+
+func (s *testSSHServer) handleSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	for req := range requests {
+		switch req.Type {
+		case "pty-req":
+			_ = req.Reply(true, nil)
+		case "window-change":
+			var size struct{ Columns, Rows, Width, Height uint32 }
+			if err := ssh.Unmarshal(req.Payload, &size); err == nil {
+				s.lock.Lock()
+				s.resizes = append(s.resizes, [2]uint32{size.Columns, size.Rows})
+				s.lock.Unlock()
+			}
+		case "shell":
+			_ = req.Reply(true, nil)
+			go s.echo(channel)
+		case "exec":
+			_ = req.Reply(true, nil)
+			_, _ = channel.Write([]byte(testFacts))
+			_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+			_ = channel.Close()
+		default:
+			_ = req.Reply(false, nil)
+		}
+	}
+}
+
+func (s *testSSHServer) echo(channel ssh.Channel) {
+	defer channel.Close()
+
+	buf := make([]byte, 1024)
+	var input bytes.Buffer
+	for {
+		n, err := channel.Read(buf)
+		if err != nil {
+			return
+		}
+		_, _ = channel.Write(buf[:n])
+		input.Write(buf[:n])
+		if strings.Contains(input.String(), "exit\n") {
+			_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+			return
+		}
+	}
+}
+
+func (s *testSSHServer) recordAuth() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.authAttempts++
+}
+
+func (s *testSSHServer) authCount() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.authAttempts
+}
+
+func (s *testSSHServer) windowSizes() [][2]uint32 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([][2]uint32{}, s.resizes...)
+}
+
+func (s *testSSHServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func message(msgType byte, body []byte) []byte {
+	return append([]byte{msgType}, base64.StdEncoding.EncodeToString(body)...)
+}
+
+func TestSSH(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(privateKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name string
+		auth types.HostAuth
+	}{
+		{
+			name: "password",
+			auth: types.HostAuth{Type: types.PasswordAuth, Password: &types.PasswordSpec{User: testUser, Password: testPassword}},
+		},
+		{
+			name: "private key",
+			auth: types.HostAuth{Type: types.KeyAuth, Key: &types.KeySpec{User: testUser, Data: string(pem.EncodeToMemory(block))}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestSSHServer(t, sshPublicKey)
+
+			object := &model.Host{Name: "node-1", Address: "127.0.0.1", Port: server.port(), HostKey: string(ssh.MarshalAuthorizedKey(server.hostKey))}
+			object.Id = 1
+			if err := setCredential(object, &tc.auth); err != nil {
+				t.Fatal(err)
+			}
+			dao := &fakeHostDao{object: object}
+			h := NewHost(config.Config{}, &fakeFactory{host: dao})
+
+			meta := SessionMeta{Operator: "alice", RequestId: "req-1"}
+			done := make(chan error, 1)
+			upgrader := websocket.Upgrader{}
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				conn, err := upgrader.Upgrade(w, r, nil)
+				if err != nil {
+					done <- err
+					return
+				}
+				done <- h.SSH(context.Background(), object.Id, conn, meta)
+			}))
+			defer ts.Close()
+
+			conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http"), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			resize, _ := json.Marshal(types.Resize{Columns: 120, Rows: 40})
+			for _, msg := range [][]byte{
+				message(types.MsgResize, resize),
+				message(types.MsgData, []byte("echo hi\n")),
+				message(types.MsgData, []byte("exit\n")),
+			} {
+				if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// 读取回显直到 ssh 会话结束后服务端关闭 websocket
+			var output bytes.Buffer
+			_ = conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+			for {
+				_, data, err := conn.ReadMessage()
+				if err != nil {
+					break
+				}
+				output.Write(data)
+			}
+
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Fatalf("SSH returned error: %v", err)
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatal("SSH did not return after the shell exited")
+			}
+
+			if got := output.String(); got != "echo hi\nexit\n" {
+				t.Errorf("unexpected terminal output %q", got)
+			}
+			if sizes := server.windowSizes(); len(sizes) != 1 || sizes[0] != [2]uint32{120, 40} {
+				t.Errorf("unexpected window changes %v", sizes)
+			}
+
+			if len(dao.sessions) != 1 {
+				t.Fatalf("expected 1 recorded session, got %d", len(dao.sessions))
+			}
+			session := dao.sessions[0]
+			if session.HostId != object.Id || session.Operator != meta.Operator || session.RequestId != meta.RequestId {
+				t.Errorf("unexpected session meta %+v", session)
+			}
+			if session.Input != "echo hi\nexit\n" {
+				t.Errorf("unexpected recorded input %q", session.Input)
+			}
+			if session.EndTime.Before(session.StartTime) {
+				t.Errorf("session ends before it starts: %v - %v", session.StartTime, session.EndTime)
+			}
+		})
+	}
+}
+
+func TestSSHHostNotFound(t *testing.T) {
+	object := &model.Host{Name: "node-1", Address: "127.0.0.1", Port: 22}
+	object.Id = 1
+	h := NewHost(config.Config{}, &fakeFactory{host: &fakeHostDao{object: object}})
+
+	if err := h.SSH(context.Background(), 2, nil, SessionMeta{}); err == nil {
+		t.Fatal("expected error for missing host")
+	}
+}
+
+func otherHostKey(t *testing.T) string {
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(ssh.MarshalAuthorizedKey(key))
+}
+
+// TestSSHHostKey 未记录或者不一致的 host key 在认证前拒绝连接，不会发送主机的登陆凭证
+func TestSSHHostKey(t *testing.T) {
+	testCases := []struct {
+		name    string
+		hostKey func(server *testSSHServer) string
+	}{
+		{
+			name:    "not recorded",
+			hostKey: func(*testSSHServer) string { return "" },
+		},
+		{
+			name:    "mismatched",
+			hostKey: func(*testSSHServer) string { return otherHostKey(t) },
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestSSHServer(t, nil)
+			object := &model.Host{
+				Name:     "node-1",
+				Address:  "127.0.0.1",
+				Port:     server.port(),
+				AuthType: string(types.PasswordAuth),
+				User:     testUser,
+				Password: testPassword,
+				HostKey:  tc.hostKey(server),
+			}
+			object.Id = 1
+			dao := &fakeHostDao{object: object}
+			h := NewHost(config.Config{}, &fakeFactory{host: dao})
+
+			if err := h.SSH(context.Background(), object.Id, nil, SessionMeta{}); err == nil {
+				t.Fatal("expected ssh to be rejected")
+			}
+			if n := server.authCount(); n != 0 {
+				t.Errorf("expected no authentication attempts, got %d", n)
+			}
+			if len(dao.sessions) != 0 {
+				t.Errorf("expected no recorded session, got %d", len(dao.sessions))
+			}
+		})
+	}
+}
+
+// TestCheckHostKey 第一次检查成功时记录 host key，之后的检查按照记录的 host key 校验
+func TestCheckHostKey(t *testing.T) {
+	testCases := []struct {
+		name    string
+		hostKey func(server *testSSHServer) string
+		// 期望记录的 host key，为空表示不更新
+		expectedHostKey func(server *testSSHServer) string
+		expectedStatus  model.HostStatus
+		expectedAuth    bool
+	}{
+		{
+			name:            "trust on first use",
+			hostKey:         func(*testSSHServer) string { return "" },
+			expectedHostKey: func(server *testSSHServer) string { return string(ssh.MarshalAuthorizedKey(server.hostKey)) },
+			expectedStatus:  model.HostStatusOnline,
+			expectedAuth:    true,
+		},
+		{
+			name:            "recorded",
+			hostKey:         func(server *testSSHServer) string { return string(ssh.MarshalAuthorizedKey(server.hostKey)) },
+			expectedHostKey: func(*testSSHServer) string { return "" },
+			expectedStatus:  model.HostStatusOnline,
+			expectedAuth:    true,
+		},
+		{
+			name:            "mismatched",
+			hostKey:         func(*testSSHServer) string { return otherHostKey(t) },
+			expectedHostKey: func(*testSSHServer) string { return "" },
+			expectedStatus:  model.HostStatusOffline,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestSSHServer(t, nil)
+			object := &model.Host{
+				Name:     "node-1",
+				Address:  "127.0.0.1",
+				Port:     server.port(),
+				AuthType: string(types.PasswordAuth),
+				User:     testUser,
+				Password: testPassword,
+				HostKey:  tc.hostKey(server),
+			}
+			object.Id = 1
+			dao := &fakeHostDao{object: object}
+			h := NewHost(config.Config{}, &fakeFactory{host: dao})
+
+			if _, err := h.Check(context.Background(), object.Id); err != nil {
+				t.Fatalf("check returned error: %v", err)
+			}
+			if len(dao.updates) != 1 {
+				t.Fatalf("expected 1 update, got %d", len(dao.updates))
+			}
+			updates := dao.updates[0]
+			if status := updates["status"]; status != tc.expectedStatus {
+				t.Errorf("expected status %v, got %v (%v)", tc.expectedStatus, status, updates["message"])
+			}
+			hostKey, _ := updates["host_key"].(string)
+			if expected := tc.expectedHostKey(server); hostKey != expected {
+				t.Errorf("expected recorded host key %q, got %q", expected, hostKey)
+			}
+			if authed := server.authCount() != 0; authed != tc.expectedAuth {
+				t.Errorf("expected authentication %v, got %v", tc.expectedAuth, authed)
+			}
+			if tc.expectedStatus == model.HostStatusOnline && !strings.Contains(updates["facts"].(string), "Test Linux") {
+				t.Errorf("unexpected facts %v", updates["facts"])
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	yamlutil "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+
+	"kubevulpes/api/errors"
+	"kubevulpes/api/httputils"
+	"kubevulpes/pkg/controller/cluster"
+	"kubevulpes/pkg/db/model"
+	"kubevulpes/pkg/types"
+)
+
+const (
+	fieldManager = "kubevulpes"
+
+	ApplyActionCreated    = "created"
+	ApplyActionConfigured = "configured"
+	ApplyActionUnchanged  = "unchanged"
+	ApplyActionFailed     = "failed"
+)
+
+// Apply 按照顺序应用 manifest 中的每个对象，单个对象失败时记录错误并继续应用后续对象
+func (k *kube) Apply(ctx context.Context, clusterName string, req *types.ApplyRequest) ([]types.ApplyResult, error) {
+	user, err := httputils.GetUserFromRequest(ctx)
+	if err != nil {
+		return nil, errors.NewError(err, http.StatusUnauthorized)
+	}
+	cs, ok := cluster.Indexer().Get(clusterName)
+	if !ok {
+		return nil, errors.ErrClusterNotFound
+	}
+	objects, err := decodeManifest(req.Manifest)
+	if err != nil {
+		return nil, errors.NewError(err, http.StatusBadRequest)
+	}
+
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(cs.Client.Discovery()))
+	// manifest 中的对象按照对象的资源类型和命名空间逐个鉴权
+	authorize := func(resource, namespace string, op model.Operation) error {
+		ok, err := k.enforcer.Enforce(user.Name, resource, clusterName, op.String(), namespace)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("无 %s 的 %s 权限", resource, op)
+		}
+		return nil
+	}
+	results := make([]types.ApplyResult, 0, len(objects))
+	for _, object := range objects {
+		results = append(results, applyObject(ctx, cs.Dynamic, mapper, object, req, authorize))
+	}
+	return results, nil
+}
+
+// decodeManifest 解析多文档的 yaml 或者 json，忽略空文档并展开 List 类型
+func decodeManifest(manifest string) ([]*unstructured.Unstructured, error) {
+	decoder := yamlutil.NewYAMLOrJSONDecoder(strings.NewReader(manifest), 4096)
+
+	objects := make([]*unstructured.Unstructured, 0)
+	for i := 1; ; i++ {
+		var raw runtime.RawExtension
+		if err := decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("第 %d 个文档解析失败: %v", i, err)
+		}
+		raw.Raw = bytes.TrimSpace(raw.Raw)
+		if len(raw.Raw) == 0 || bytes.Equal(raw.Raw, []byte("null")) {
+			continue
+		}
+
+		object := &unstructured.Unstructured{}
+		if err := object.UnmarshalJSON(raw.Raw); err != nil {
+			return nil, fmt.Errorf("第 %d 个文档解析失败: %v", i, err)
+		}
+		if object.IsList() {
+			if err := object.EachListItem(func(item runtime.Object) error {
+				objects = append(objects, item.(*unstructured.Unstructured))
+				return nil
+			}); err != nil {
+				return nil, fmt.Errorf("第 %d 个文档解析失败: %v", i, err)
+			}
+			continue
+		}
+		objects = append(objects, object)
+	}
+
+	for _, object := range objects {
+		if len(object.GetName()) == 0 {
+			return nil, fmt.Errorf("%s 缺少 metadata.name", object.GetKind())
+		}
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("manifest 中没有需要应用的对象")
+	}
+	return objects, nil
+}
+
+func applyObject(ctx context.Context, client dynamic.Interface, mapper *restmapper.DeferredDiscoveryRESTMapper, object *unstructured.Unstructured, req *types.ApplyRequest,
+	authorize func(resource, namespace string, op model.Operation) error) types.ApplyResult {
+	result := types.ApplyResult{
+		APIVersion: object.GetAPIVersion(),
+		Kind:       object.GetKind(),
+		Name:       object.GetName(),
+	}
+	failed := func(err error) types.ApplyResult {
+		result.Action = ApplyActionFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	ri, resource, err := resourceInterface(client, mapper, object, req.Namespace)
+	if err != nil {
+		return failed(err)
+	}
+	result.Namespace = object.GetNamespace()
+
+	live, err := ri.Get(ctx, object.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return failed(err)
+		}
+		live = nil
+	}
+	op := model.OpUpdate
+	if live == nil {
+		op = model.OpCreate
+	}
+	if err = authorize(resource, object.GetNamespace(), op); err != nil {
+		return failed(err)
+	}
+
+	opts := metav1.ApplyOptions{FieldManager: fieldManager, Force: req.Force}
+	if req.DryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	applied, err := ri.Apply(ctx, object.GetName(), object, opts)
+	if err != nil {
+		return failed(err)
+	}
+	result.ResourceVersion = applied.GetResourceVersion()
+
+	diff := diffObjects(live, applied)
+	switch {
+	case live == nil:
+		result.Action = ApplyActionCreated
+	case len(diff) == 0:
+		result.Action = ApplyActionUnchanged
+	default:
+		result.Action = ApplyActionConfigured
+	}
+	if req.DryRun {
+		result.Diff = diff
+	}
+	return result
+}
+
+// resourceInterface 根据对象的 GVK 找到对应的资源，并补全命名空间，同时返回资源的复数名称
+func resourceInterface(client dynamic.Interface, mapper *restmapper.DeferredDiscoveryRESTMapper, object *unstructured.Unstructured, namespace string) (dynamic.ResourceInterface, string, error) {
+	gvk := object.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		// CRD 可能由同一个 manifest 中靠前的对象创建，刷新后重试
+		mapper.Reset()
+		mapping, err = mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		object.SetNamespace("")
+		return client.Resource(mapping.Resource), mapping.Resource.Resource, nil
+	}
+
+	switch {
+	case len(object.GetNamespace()) == 0 && len(namespace) == 0:
+		object.SetNamespace(metav1.NamespaceDefault)
+	case len(object.GetNamespace()) == 0:
+		object.SetNamespace(namespace)
+	case len(namespace) != 0 && object.GetNamespace() != namespace:
+		return nil, "", fmt.Errorf("对象的命名空间 %s 与指定的命名空间 %s 不一致", object.GetNamespace(), namespace)
+	}
+	return client.Resource(mapping.Resource).Namespace(object.GetNamespace()), mapping.Resource.Resource, nil
+}
+
+// diffObjects 比较线上对象和应用后的对象，忽略系统维护的元数据
+func diffObjects(live, applied *unstructured.Unstructured) string {
+	before := map[string]interface{}{}
+	if live != nil {
+		before = stripObject(live)
+	}
+	return cmp.Diff(before, stripObject(applied))
+}
+
+func stripObject(object *unstructured.Unstructured) map[string]interface{} {
+	stripped := object.DeepCopy()
+	for _, field := range []string{"managedFields", "resourceVersion", "generation", "uid", "creationTimestamp", "selfLink"} {
+		unstructured.RemoveNestedField(stripped.Object, "metadata", field)
+	}
+	return stripped.Object
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	"kubevulpes/api/errors"
+	"kubevulpes/api/httputils"
+	"kubevulpes/pkg/client"
+	"kubevulpes/pkg/types"
+)
+
+// Events 从 informer 缓存中读取事件
+// 指定 uid 或者 name 时返回该对象的事件，仅指定 namespace 时返回命名空间的事件，否则返回整个集群的事件
+func (k *kube) Events(ctx context.Context, clusterName string, opts *types.EventOptions) (*types.EventList, error) {
+	cs, err := getClusterSet(ctx, clusterName, client.EventsResource)
+	if err != nil {
+		return nil, err
+	}
+
+	// 集群级别对象（例如 node）的事件不在对象所在的命名空间中，需要查询全部命名空间
+	namespace := opts.Namespace
+	if isObjectEvents(opts) && !opts.Namespaced {
+		namespace = v1.NamespaceAll
+	}
+	lister, err := cs.Informer.EventsLister()
+	if err != nil {
+		klog.Errorf("failed to list events of cluster(%s): %v", clusterName, err)
+		return nil, errors.ErrServerInternal
+	}
+	events, err := lister.Events(namespace).List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list events of cluster(%s): %v", clusterName, err)
+		return nil, errors.ErrServerInternal
+	}
+
+	selector := involvedObjectSelector(opts)
+	inRange, namespaces := httputils.GetNamespaceRangeFromListReq(ctx)
+	items := make([]v1.Event, 0)
+	for _, event := range events {
+		if inRange && !inNamespaceRange(event.Namespace, namespaces) {
+			continue
+		}
+		if selector.Matches(involvedObjectFields(event)) {
+			items = append(items, *event)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return lastTimestamp(&items[i]).After(lastTimestamp(&items[j]))
+	})
+
+	reasons := summarizeEvents(items)
+	if opts.Limit > 0 && int64(len(items)) > opts.Limit {
+		items = items[:opts.Limit]
+	}
+	return &types.EventList{
+		Items:   items,
+		Reasons: reasons,
+	}, nil
+}
+
+func isObjectEvents(opts *types.EventOptions) bool {
+	return len(opts.Uid) != 0 || len(opts.Name) != 0
+}
+
+// involvedObjectSelector 与 apiserver 支持的 involvedObject 字段选择器保持一致
+func involvedObjectSelector(opts *types.EventOptions) fields.Selector {
+	set := fields.Set{}
+	if !isObjectEvents(opts) {
+		return set.AsSelector()
+	}
+
+	if len(opts.Uid) != 0 {
+		set["involvedObject.uid"] = opts.Uid
+	}
+	if len(opts.Name) != 0 {
+		set["involvedObject.name"] = opts.Name
+	}
+	if len(opts.Kind) != 0 {
+		set["involvedObject.kind"] = opts.Kind
+	}
+	if opts.Namespaced {
+		set["involvedObject.namespace"] = opts.Namespace
+	}
+	return set.AsSelector()
+}
+
+func involvedObjectFields(event *v1.Event) fields.Set {
+	return fields.Set{
+		"involvedObject.uid":       string(event.InvolvedObject.UID),
+		"involvedObject.name":      event.InvolvedObject.Name,
+		"involvedObject.kind":      event.InvolvedObject.Kind,
+		"involvedObject.namespace": event.InvolvedObject.Namespace,
+	}
+}
+
+// lastTimestamp 通过 events.k8s.io 上报的事件可能没有设置 lastTimestamp
+func lastTimestamp(event *v1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case event.Series != nil && !event.Series.LastObservedTime.IsZero():
+		return event.Series.LastObservedTime.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
+
+// summarizeEvents 按照 reason 和 type 聚合事件，items 需要已经按照时间倒序排列
+func summarizeEvents(items []v1.Event) []types.EventSummary {
+	index := make(map[string]int)
+	summaries := make([]types.EventSummary, 0)
+	for i := range items {
+		event := &items[i]
+		count := event.Count
+		if event.Series != nil && event.Series.Count > count {
+			count = event.Series.Count
+		}
+		if count == 0 {
+			count = 1
+		}
+
+		key := event.Type + "/" + event.Reason
+		if idx, ok := index[key]; ok {
+			summaries[idx].Count += count
+			continue
+		}
+		index[key] = len(summaries)
+		summaries = append(summaries, types.EventSummary{
+			Reason:        event.Reason,
+			Type:          event.Type,
+			Count:         count,
+			LastTimestamp: lastTimestamp(event),
+		})
+	}
+	return summaries
+}
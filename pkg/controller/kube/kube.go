@@ -0,0 +1,244 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	"kubevulpes/api/errors"
+	"kubevulpes/api/httputils"
+	"kubevulpes/cmd/app/config"
+	"kubevulpes/pkg/client"
+	"kubevulpes/pkg/controller/cluster"
+	"kubevulpes/pkg/db"
+	"kubevulpes/pkg/db/model"
+	"kubevulpes/pkg/types"
+)
+
+type KubeGetter interface {
+	Kube() Interface
+}
+
+// Interface 读取 kubernetes 资源，数据来自集群的 informer 缓存，不直接访问 apiserver
+type Interface interface {
+	List(ctx context.Context, resource string, meta types.VulpesObjectMeta, listOptions *types.ListOptions) (*types.PageResponse, error)
+	Get(ctx context.Context, resource string, meta types.VulpesObjectMeta) (metav1.Object, error)
+
+	// ListResources 和 GetResource 读取集群配置缓存的任意资源，包括 CRD
+	ListResources(ctx context.Context, meta types.ResourceMeta, listOptions *types.ListOptions) (*types.PageResponse, error)
+	GetResource(ctx context.Context, meta types.ResourceMeta) (runtime.Object, error)
+
+	Apply(ctx context.Context, clusterName string, req *types.ApplyRequest) ([]types.ApplyResult, error)
+
+	Scale(ctx context.Context, resource string, meta types.VulpesObjectMeta, replicas int32) (*types.ObjectVersionResponse, error)
+	Restart(ctx context.Context, resource string, meta types.VulpesObjectMeta) (*types.ObjectVersionResponse, error)
+	Pause(ctx context.Context, meta types.VulpesObjectMeta, paused bool) (*types.ObjectVersionResponse, error)
+	DeletePod(ctx context.Context, meta types.VulpesObjectMeta, req *types.DeletePodRequest) (*types.ObjectVersionResponse, error)
+	EvictPod(ctx context.Context, meta types.VulpesObjectMeta) (*types.ObjectVersionResponse, error)
+
+	ClusterUsage(ctx context.Context, clusterName string) (*types.ResourceUsage, error)
+	NodeUsage(ctx context.Context, clusterName string, name string) (*types.ResourceUsage, error)
+	NamespaceUsage(ctx context.Context, clusterName string, namespace string) (*types.ResourceUsage, error)
+
+	Events(ctx context.Context, clusterName string, opts *types.EventOptions) (*types.EventList, error)
+	Logs(ctx context.Context, meta types.VulpesObjectMeta, opts *types.PodLogOptions) (io.ReadCloser, error)
+	Terminal(ctx context.Context, opts *types.WebShellOptions, session *types.TerminalSession) error
+}
+
+// 首次访问或者缓存被回收后，等待 informer 同步的超时时间
+const cacheSyncTimeout = 30 * time.Second
+
+type kube struct {
+	cc       config.Config
+	factory  db.ShareDaoFactory
+	enforcer *casbin.SyncedEnforcer
+}
+
+func (k *kube) List(ctx context.Context, resource string, meta types.VulpesObjectMeta, listOptions *types.ListOptions) (*types.PageResponse, error) {
+	lister, cs, err := k.getLister(ctx, resource, meta.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	selector, err := labels.Parse(listOptions.LabelSelector)
+	if err != nil {
+		return nil, errors.NewError(fmt.Errorf("标签选择器不合法: %v", err), http.StatusBadRequest)
+	}
+
+	objects, err := lister.list(cs.Informer, meta.Namespace, selector)
+	if err != nil {
+		klog.Errorf("failed to list %s of cluster(%s): %v", resource, meta.Cluster, err)
+		return nil, errors.ErrServerInternal
+	}
+	objects = filterByNamespaceRange(ctx, objects, resource == ResourceNamespaces)
+	return pageObjects(objects, listOptions)
+}
+
+func (k *kube) Get(ctx context.Context, resource string, meta types.VulpesObjectMeta) (metav1.Object, error) {
+	lister, cs, err := k.getLister(ctx, resource, meta.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := lister.get(cs.Informer, meta.Namespace, meta.Name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errors.NewError(err, http.StatusNotFound)
+		}
+		klog.Errorf("failed to get %s %s/%s of cluster(%s): %v", resource, meta.Namespace, meta.Name, meta.Cluster, err)
+		return nil, errors.ErrServerInternal
+	}
+	return object, nil
+}
+
+func (k *kube) getLister(ctx context.Context, resource string, clusterName string) (*resourceLister, client.ClusterSet, error) {
+	lister, ok := resourceListers[resource]
+	if !ok {
+		return nil, client.ClusterSet{}, errors.NewError(fmt.Errorf("不支持的资源类型 %s", resource), http.StatusBadRequest)
+	}
+	cs, err := getClusterSet(ctx, clusterName, lister.gvr)
+	if err != nil {
+		return nil, client.ClusterSet{}, err
+	}
+	return lister, cs, nil
+}
+
+// getClusterSet 获取集群的 clusterSet，检查依赖的资源是否被缓存，并等待缓存同步完成
+// 长时间未访问的集群缓存会被回收，再次访问时重新同步
+func getClusterSet(ctx context.Context, clusterName string, resources ...schema.GroupVersionResource) (client.ClusterSet, error) {
+	cs, ok := cluster.Indexer().Get(clusterName)
+	if !ok {
+		return client.ClusterSet{}, errors.ErrClusterNotFound
+	}
+	for _, gvr := range resources {
+		if !cs.Informer.HasResource(gvr) {
+			return client.ClusterSet{}, errors.NewError(fmt.Errorf("集群未缓存资源 %s", client.FormatResource(gvr)), http.StatusBadRequest)
+		}
+	}
+
+	syncCtx, cancel := context.WithTimeout(ctx, cacheSyncTimeout)
+	defer cancel()
+	if err := cs.Informer.Sync(syncCtx, resources...); err != nil {
+		klog.Warningf("cache of cluster(%s) is not ready: %v", clusterName, err)
+		return client.ClusterSet{}, errors.NewError(fmt.Errorf("集群缓存同步中，请稍后重试"), http.StatusServiceUnavailable)
+	}
+	return cs, nil
+}
+
+// pageObjects 按照名称过滤、排序并分页
+func pageObjects(objects []metav1.Object, listOptions *types.ListOptions) (*types.PageResponse, error) {
+	objects = filterByName(objects, listOptions.NameSelector)
+	sortObjects(objects, listOptions.IsDesc())
+
+	total := len(objects)
+	if listOptions.IsPaged() {
+		offset, end, err := listOptions.Offset(total)
+		if err != nil {
+			return nil, errors.NewError(err, http.StatusBadRequest)
+		}
+		objects = objects[offset:end]
+	}
+
+	return &types.PageResponse{
+		Total:       total,
+		Items:       objects,
+		PageRequest: listOptions.PageRequest,
+	}, nil
+}
+
+// filterByName 按照名称子串过滤
+func filterByName(objects []metav1.Object, name string) []metav1.Object {
+	if len(name) == 0 {
+		return objects
+	}
+
+	filtered := make([]metav1.Object, 0)
+	for _, object := range objects {
+		if strings.Contains(object.GetName(), name) {
+			filtered = append(filtered, object)
+		}
+	}
+	return filtered
+}
+
+// filterByNamespaceRange 按照鉴权设置的命名空间范围过滤，命名空间对象按照自身名称过滤
+func filterByNamespaceRange(ctx context.Context, objects []metav1.Object, isNamespace bool) []metav1.Object {
+	exists, namespaces := httputils.GetNamespaceRangeFromListReq(ctx)
+	if !exists {
+		return objects
+	}
+
+	filtered := make([]metav1.Object, 0)
+	for _, object := range objects {
+		namespace := object.GetNamespace()
+		if isNamespace {
+			namespace = object.GetName()
+		}
+		if inNamespaceRange(namespace, namespaces) {
+			filtered = append(filtered, object)
+		}
+	}
+	return filtered
+}
+
+// inNamespaceRange 策略中的命名空间支持正则表达式，集群级别的对象不在任何命名空间范围内
+func inNamespaceRange(namespace string, namespaces []string) bool {
+	if len(namespace) == 0 {
+		return false
+	}
+	for _, pattern := range namespaces {
+		if model.KeyMatch(namespace, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortObjects 按照创建时间排序，创建时间相同时按照名称排序
+func sortObjects(objects []metav1.Object, desc bool) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		ti, tj := objects[i].GetCreationTimestamp(), objects[j].GetCreationTimestamp()
+		if ti.Equal(&tj) {
+			return objects[i].GetName() < objects[j].GetName()
+		}
+		if desc {
+			return tj.Before(&ti)
+		}
+		return ti.Before(&tj)
+	})
+}
+
+func NewKube(cfg config.Config, f db.ShareDaoFactory, e *casbin.SyncedEnforcer) *kube {
+	return &kube{
+		cc:       cfg,
+		factory:  f,
+		enforcer: e,
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"kubevulpes/pkg/client"
+)
+
+const (
+	ResourcePods         = "pods"
+	ResourceNodes        = "nodes"
+	ResourceNamespaces   = "namespaces"
+	ResourceDeployments  = "deployments"
+	ResourceStatefulSets = "statefulsets"
+	ResourceDaemonSets   = "daemonsets"
+	ResourceJobs         = "jobs"
+	ResourceCronJobs     = "cronjobs"
+)
+
+// NamespacedResources 返回支持浏览的命名空间级别资源
+func NamespacedResources() []string {
+	return []string{
+		ResourcePods,
+		ResourceDeployments,
+		ResourceStatefulSets,
+		ResourceDaemonSets,
+		ResourceJobs,
+		ResourceCronJobs,
+	}
+}
+
+// resourceLister 从 informer 缓存中读取指定类型的资源
+// 集群级别的资源忽略 namespace 参数
+type resourceLister struct {
+	gvr  schema.GroupVersionResource
+	list func(informer *client.VuplesInformer, namespace string, selector labels.Selector) ([]metav1.Object, error)
+	get  func(informer *client.VuplesInformer, namespace string, name string) (metav1.Object, error)
+}
+
+var resourceListers = map[string]*resourceLister{
+	ResourcePods: {
+		gvr: client.PodsResource,
+		list: func(informer *client.VuplesInformer, namespace string, selector labels.Selector) ([]metav1.Object, error) {
+			lister, err := informer.PodsLister()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(lister.Pods(namespace).List(selector))
+		},
+		get: func(informer *client.VuplesInformer, namespace string, name string) (metav1.Object, error) {
+			lister, err := informer.PodsLister()
+			if err != nil {
+				return nil, err
+			}
+			return lister.Pods(namespace).Get(name)
+		},
+	},
+	ResourceNodes: {
+		gvr: client.NodesResource,
+		list: func(informer *client.VuplesInformer, _ string, selector labels.Selector) ([]metav1.Object, error) {
+			lister, err := informer.NodesLister()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(lister.List(selector))
+		},
+		get: func(informer *client.VuplesInformer, _ string, name string) (metav1.Object, error) {
+			lister, err := informer.NodesLister()
+			if err != nil {
+				return nil, err
+			}
+			return lister.Get(name)
+		},
+	},
+	ResourceNamespaces: {
+		gvr: client.NamespacesResource,
+		list: func(informer *client.VuplesInformer, _ string, selector labels.Selector) ([]metav1.Object, error) {
+			lister, err := informer.NamespacesLister()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(lister.List(selector))
+		},
+		get: func(informer *client.VuplesInformer, _ string, name string) (metav1.Object, error) {
+			lister, err := informer.NamespacesLister()
+			if err != nil {
+				return nil, err
+			}
+			return lister.Get(name)
+		},
+	},
+	ResourceDeployments: {
+		gvr: client.DeploymentsResource,
+		list: func(informer *client.VuplesInformer, namespace string, selector labels.Selector) ([]metav1.Object, error) {
+			lister, err := informer.DeploymentsLister()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(lister.Deployments(namespace).List(selector))
+		},
+		get: func(informer *client.VuplesInformer, namespace string, name string) (metav1.Object, error) {
+			lister, err := informer.DeploymentsLister()
+			if err != nil {
+				return nil, err
+			}
+			return lister.Deployments(namespace).Get(name)
+		},
+	},
+	ResourceStatefulSets: {
+		gvr: client.StatefulSetsResource,
+		list: func(informer *client.VuplesInformer, namespace string, selector labels.Selector) ([]metav1.Object, error) {
+			lister, err := informer.StatefulSetsLister()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(lister.StatefulSets(namespace).List(selector))
+		},
+		get: func(informer *client.VuplesInformer, namespace string, name string) (metav1.Object, error) {
+			lister, err := informer.StatefulSetsLister()
+			if err != nil {
+				return nil, err
+			}
+			return lister.StatefulSets(namespace).Get(name)
+		},
+	},
+	ResourceDaemonSets: {
+		gvr: client.DaemonSetsResource,
+		list: func(informer *client.VuplesInformer, namespace string, selector labels.Selector) ([]metav1.Object, error) {
+			lister, err := informer.DaemonSetsLister()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(lister.DaemonSets(namespace).List(selector))
+		},
+		get: func(informer *client.VuplesInformer, namespace string, name string) (metav1.Object, error) {
+			lister, err := informer.DaemonSetsLister()
+			if err != nil {
+				return nil, err
+			}
+			return lister.DaemonSets(namespace).Get(name)
+		},
+	},
+	ResourceJobs: {
+		gvr: client.JobsResource,
+		list: func(informer *client.VuplesInformer, namespace string, selector labels.Selector) ([]metav1.Object, error) {
+			lister, err := informer.JobsLister()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(lister.Jobs(namespace).List(selector))
+		},
+		get: func(informer *client.VuplesInformer, namespace string, name string) (metav1.Object, error) {
+			lister, err := informer.JobsLister()
+			if err != nil {
+				return nil, err
+			}
+			return lister.Jobs(namespace).Get(name)
+		},
+	},
+	ResourceCronJobs: {
+		gvr: client.CronJobsResource,
+		list: func(informer *client.VuplesInformer, namespace string, selector labels.Selector) ([]metav1.Object, error) {
+			lister, err := informer.CronJobsLister()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(lister.CronJobs(namespace).List(selector))
+		},
+		get: func(informer *client.VuplesInformer, namespace string, name string) (metav1.Object, error) {
+			lister, err := informer.CronJobsLister()
+			if err != nil {
+				return nil, err
+			}
+			return lister.CronJobs(namespace).Get(name)
+		},
+	},
+}
+
+func toObjects[T metav1.Object](items []T, err error) ([]metav1.Object, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]metav1.Object, len(items))
+	for i, item := range items {
+		objects[i] = item
+	}
+	return objects, nil
+}
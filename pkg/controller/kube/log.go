@@ -0,0 +1,76 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"kubevulpes/api/errors"
+	"kubevulpes/pkg/controller/cluster"
+	"kubevulpes/pkg/types"
+)
+
+// Logs 返回容器日志的数据流，调用方负责关闭
+// ctx 取消时 apiserver 的连接随之关闭，follow 模式下调用方需要在客户端断开时取消 ctx
+func (k *kube) Logs(ctx context.Context, meta types.VulpesObjectMeta, opts *types.PodLogOptions) (io.ReadCloser, error) {
+	cs, ok := cluster.Indexer().Get(meta.Cluster)
+	if !ok {
+		return nil, errors.ErrClusterNotFound
+	}
+
+	logOptions := &v1.PodLogOptions{
+		Container:  opts.Container,
+		Follow:     opts.Follow,
+		Previous:   opts.Previous,
+		Timestamps: opts.Timestamps,
+	}
+	if opts.TailLines > 0 {
+		logOptions.TailLines = &opts.TailLines
+	}
+	if opts.LimitBytes > 0 {
+		logOptions.LimitBytes = &opts.LimitBytes
+	}
+	if len(opts.SinceTime) != 0 {
+		since, err := time.Parse(time.RFC3339, opts.SinceTime)
+		if err != nil {
+			return nil, errors.NewError(fmt.Errorf("sinceTime 必须为 RFC3339 格式: %v", err), http.StatusBadRequest)
+		}
+		logOptions.SinceTime = &metav1.Time{Time: since}
+	}
+
+	stream, err := cs.Client.CoreV1().Pods(meta.Namespace).GetLogs(meta.Name, logOptions).Stream(ctx)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errors.NewError(err, http.StatusNotFound)
+		}
+		if apierrors.IsBadRequest(err) {
+			return nil, errors.NewError(err, http.StatusBadRequest)
+		}
+		klog.Errorf("failed to get logs of pod %s/%s in cluster(%s): %v", meta.Namespace, meta.Name, meta.Cluster, err)
+		return nil, err
+	}
+	return stream, nil
+}
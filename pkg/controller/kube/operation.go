@@ -0,0 +1,201 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"kubevulpes/api/errors"
+	"kubevulpes/pkg/controller/cluster"
+	"kubevulpes/pkg/types"
+)
+
+// 与 kubectl rollout restart 使用相同的注解
+const restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// workloadPatcher 对工作负载执行 patch，返回 patch 之后的对象版本
+type workloadPatcher func(ctx context.Context, client kubernetes.Interface, namespace, name string, pt apitypes.PatchType, data []byte) (*types.ObjectVersionResponse, error)
+
+var workloadPatchers = map[string]workloadPatcher{
+	ResourceDeployments: func(ctx context.Context, client kubernetes.Interface, namespace, name string, pt apitypes.PatchType, data []byte) (*types.ObjectVersionResponse, error) {
+		object, err := client.AppsV1().Deployments(namespace).Patch(ctx, name, pt, data, metav1.PatchOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objectVersion(object, object.Status.ObservedGeneration), nil
+	},
+	ResourceStatefulSets: func(ctx context.Context, client kubernetes.Interface, namespace, name string, pt apitypes.PatchType, data []byte) (*types.ObjectVersionResponse, error) {
+		object, err := client.AppsV1().StatefulSets(namespace).Patch(ctx, name, pt, data, metav1.PatchOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objectVersion(object, object.Status.ObservedGeneration), nil
+	},
+	ResourceDaemonSets: func(ctx context.Context, client kubernetes.Interface, namespace, name string, pt apitypes.PatchType, data []byte) (*types.ObjectVersionResponse, error) {
+		object, err := client.AppsV1().DaemonSets(namespace).Patch(ctx, name, pt, data, metav1.PatchOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objectVersion(object, object.Status.ObservedGeneration), nil
+	},
+}
+
+// WorkloadResources 返回支持重启的工作负载类型
+func WorkloadResources() []string {
+	return []string{ResourceDeployments, ResourceStatefulSets, ResourceDaemonSets}
+}
+
+// ScalableResources 返回支持调整副本数的工作负载类型
+func ScalableResources() []string {
+	return []string{ResourceDeployments, ResourceStatefulSets}
+}
+
+// Scale 调整副本数，daemonset 不支持
+func (k *kube) Scale(ctx context.Context, resource string, meta types.VulpesObjectMeta, replicas int32) (*types.ObjectVersionResponse, error) {
+	if resource == ResourceDaemonSets {
+		return nil, errors.NewError(fmt.Errorf("%s 不支持调整副本数", resource), http.StatusBadRequest)
+	}
+	return k.patchWorkload(ctx, resource, meta, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+		},
+	})
+}
+
+// Restart 通过修改 pod 模板的注解触发滚动重启
+func (k *kube) Restart(ctx context.Context, resource string, meta types.VulpesObjectMeta) (*types.ObjectVersionResponse, error) {
+	return k.patchWorkload(ctx, resource, meta, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{
+						restartedAtAnnotation: time.Now().Format(time.RFC3339),
+					},
+				},
+			},
+		},
+	})
+}
+
+// Pause 暂停或者恢复 deployment 的滚动更新
+func (k *kube) Pause(ctx context.Context, meta types.VulpesObjectMeta, paused bool) (*types.ObjectVersionResponse, error) {
+	return k.patchWorkload(ctx, ResourceDeployments, meta, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"paused": paused,
+		},
+	})
+}
+
+func (k *kube) patchWorkload(ctx context.Context, resource string, meta types.VulpesObjectMeta, patch map[string]interface{}) (*types.ObjectVersionResponse, error) {
+	patcher, ok := workloadPatchers[resource]
+	if !ok {
+		return nil, errors.NewError(fmt.Errorf("不支持的资源类型 %s", resource), http.StatusBadRequest)
+	}
+	cs, ok := cluster.Indexer().Get(meta.Cluster)
+	if !ok {
+		return nil, errors.ErrClusterNotFound
+	}
+
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	result, err := patcher(ctx, cs.Client, meta.Namespace, meta.Name, apitypes.StrategicMergePatchType, data)
+	if err != nil {
+		klog.Errorf("failed to patch %s %s/%s of cluster(%s): %v", resource, meta.Namespace, meta.Name, meta.Cluster, err)
+		return nil, toError(err)
+	}
+	return result, nil
+}
+
+// DeletePod 删除 pod，返回删除前的对象版本
+func (k *kube) DeletePod(ctx context.Context, meta types.VulpesObjectMeta, req *types.DeletePodRequest) (*types.ObjectVersionResponse, error) {
+	cs, ok := cluster.Indexer().Get(meta.Cluster)
+	if !ok {
+		return nil, errors.ErrClusterNotFound
+	}
+	pods := cs.Client.CoreV1().Pods(meta.Namespace)
+
+	pod, err := pods.Get(ctx, meta.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, toError(err)
+	}
+	// 指定 resourceVersion 作为前置条件，避免删除被重建的同名 pod
+	if err = pods.Delete(ctx, meta.Name, metav1.DeleteOptions{
+		GracePeriodSeconds: req.GracePeriodSeconds,
+		Preconditions:      &metav1.Preconditions{UID: &pod.UID, ResourceVersion: &pod.ResourceVersion},
+	}); err != nil {
+		klog.Errorf("failed to delete pod %s/%s of cluster(%s): %v", meta.Namespace, meta.Name, meta.Cluster, err)
+		return nil, toError(err)
+	}
+	return objectVersion(pod, pod.Generation), nil
+}
+
+// EvictPod 驱逐 pod，驱逐会遵守 PodDisruptionBudget 的限制
+func (k *kube) EvictPod(ctx context.Context, meta types.VulpesObjectMeta) (*types.ObjectVersionResponse, error) {
+	cs, ok := cluster.Indexer().Get(meta.Cluster)
+	if !ok {
+		return nil, errors.ErrClusterNotFound
+	}
+
+	pod, err := cs.Client.CoreV1().Pods(meta.Namespace).Get(ctx, meta.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, toError(err)
+	}
+	if err = cs.Client.PolicyV1().Evictions(meta.Namespace).Evict(ctx, &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      meta.Name,
+			Namespace: meta.Namespace,
+		},
+		DeleteOptions: &metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{UID: &pod.UID},
+		},
+	}); err != nil {
+		klog.Errorf("failed to evict pod %s/%s of cluster(%s): %v", meta.Namespace, meta.Name, meta.Cluster, err)
+		return nil, toError(err)
+	}
+	return objectVersion(pod, pod.Generation), nil
+}
+
+func objectVersion(object metav1.Object, observedGeneration int64) *types.ObjectVersionResponse {
+	return &types.ObjectVersionResponse{
+		Name:               object.GetName(),
+		Namespace:          object.GetNamespace(),
+		ResourceVersion:    object.GetResourceVersion(),
+		Generation:         object.GetGeneration(),
+		ObservedGeneration: observedGeneration,
+	}
+}
+
+// toError 保留 apiserver 返回的状态码，如 404、409 以及 PodDisruptionBudget 限制时的 429
+func toError(err error) error {
+	if status, ok := err.(apierrors.APIStatus); ok && status.Status().Code != 0 {
+		return errors.NewError(err, int(status.Status().Code))
+	}
+	return err
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"kubevulpes/api/errors"
+	"kubevulpes/pkg/types"
+)
+
+// coreGroup 核心组在路径中的名称
+const coreGroup = "core"
+
+func (k *kube) ListResources(ctx context.Context, m types.ResourceMeta, listOptions *types.ListOptions) (*types.PageResponse, error) {
+	lister, err := getGenericLister(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	selector, err := labels.Parse(listOptions.LabelSelector)
+	if err != nil {
+		return nil, errors.NewError(fmt.Errorf("标签选择器不合法: %v", err), http.StatusBadRequest)
+	}
+
+	var items []runtime.Object
+	if len(m.Namespace) == 0 {
+		items, err = lister.List(selector)
+	} else {
+		items, err = lister.ByNamespace(m.Namespace).List(selector)
+	}
+	if err != nil {
+		klog.Errorf("failed to list %s of cluster(%s): %v", m.Resource, m.Cluster, err)
+		return nil, errors.ErrServerInternal
+	}
+
+	objects := make([]metav1.Object, 0, len(items))
+	for _, item := range items {
+		object, err := meta.Accessor(item)
+		if err != nil {
+			klog.Errorf("failed to access metadata of %s: %v", m.Resource, err)
+			return nil, errors.ErrServerInternal
+		}
+		objects = append(objects, object)
+	}
+	isNamespace := (m.Group == coreGroup || len(m.Group) == 0) && m.Resource == ResourceNamespaces
+	objects = filterByNamespaceRange(ctx, objects, isNamespace)
+	return pageObjects(objects, listOptions)
+}
+
+func (k *kube) GetResource(ctx context.Context, m types.ResourceMeta) (runtime.Object, error) {
+	lister, err := getGenericLister(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+
+	var object runtime.Object
+	if len(m.Namespace) == 0 {
+		object, err = lister.Get(m.Name)
+	} else {
+		object, err = lister.ByNamespace(m.Namespace).Get(m.Name)
+	}
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errors.NewError(err, http.StatusNotFound)
+		}
+		klog.Errorf("failed to get %s %s/%s of cluster(%s): %v", m.Resource, m.Namespace, m.Name, m.Cluster, err)
+		return nil, errors.ErrServerInternal
+	}
+	return object, nil
+}
+
+func getGenericLister(ctx context.Context, m types.ResourceMeta) (cache.GenericLister, error) {
+	gvr := schema.GroupVersionResource{Group: m.Group, Version: m.Version, Resource: m.Resource}
+	if gvr.Group == coreGroup {
+		gvr.Group = ""
+	}
+
+	cs, err := getClusterSet(ctx, m.Cluster, gvr)
+	if err != nil {
+		return nil, err
+	}
+	return cs.Informer.Lister(gvr)
+}
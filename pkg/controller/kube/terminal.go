@@ -0,0 +1,98 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/klog/v2"
+
+	"kubevulpes/api/errors"
+	"kubevulpes/pkg/client"
+	"kubevulpes/pkg/types"
+)
+
+// defaultShell 未指定命令时优先使用 bash，容器中不存在 bash 时退回到 sh
+var defaultShell = []string{"/bin/sh", "-c", "command -v bash >/dev/null 2>&1 && exec bash || exec sh"}
+
+// Terminal 通过 exec 子资源进入容器，session 同时作为终端的输入、输出和窗口大小的来源
+// 阻塞直到容器中的 shell 退出或者 websocket 连接断开
+func (k *kube) Terminal(ctx context.Context, opts *types.WebShellOptions, session *types.TerminalSession) error {
+	cs, err := getClusterSet(ctx, opts.Cluster, client.PodsResource)
+	if err != nil {
+		return err
+	}
+
+	lister, err := cs.Informer.PodsLister()
+	if err != nil {
+		return err
+	}
+	pod, err := lister.Pods(opts.Namespace).Get(opts.Pod)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return errors.NewError(err, http.StatusNotFound)
+		}
+		return err
+	}
+	container := opts.Container
+	if len(container) == 0 {
+		container = pod.Spec.Containers[0].Name
+	}
+	command := defaultShell
+	if len(strings.TrimSpace(opts.Command)) != 0 {
+		command = strings.Fields(opts.Command)
+	}
+
+	req := cs.Client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(opts.Namespace).
+		Name(opts.Pod).
+		SubResource("exec").
+		VersionedParams(&v1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdin:     true,
+			Stdout:    true,
+			Stderr:    true,
+			TTY:       true,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(cs.Config, http.MethodPost, req.URL())
+	if err != nil {
+		return fmt.Errorf("failed to create executor: %v", err)
+	}
+
+	// 结束后关闭 doneChan，使 executor 的窗口大小监听退出
+	defer session.Done()
+	if err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:             session,
+		Stdout:            session,
+		Stderr:            session,
+		Tty:               true,
+		TerminalSizeQueue: session,
+	}); err != nil {
+		klog.Warningf("terminal of pod %s/%s container %s in cluster(%s) exited: %v", opts.Namespace, opts.Pod, container, opts.Cluster, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"net/http"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+
+	"kubevulpes/api/errors"
+	"kubevulpes/pkg/client"
+	"kubevulpes/pkg/types"
+	"kubevulpes/pkg/util"
+)
+
+func (k *kube) ClusterUsage(ctx context.Context, clusterName string) (*types.ResourceUsage, error) {
+	return k.usage(ctx, clusterName, func(cs client.ClusterSet) (*client.ResourceSummary, error) {
+		return cs.ClusterResources(ctx)
+	})
+}
+
+func (k *kube) NodeUsage(ctx context.Context, clusterName string, name string) (*types.ResourceUsage, error) {
+	return k.usage(ctx, clusterName, func(cs client.ClusterSet) (*client.ResourceSummary, error) {
+		return cs.NodeResources(ctx, name)
+	})
+}
+
+func (k *kube) NamespaceUsage(ctx context.Context, clusterName string, namespace string) (*types.ResourceUsage, error) {
+	return k.usage(ctx, clusterName, func(cs client.ClusterSet) (*client.ResourceSummary, error) {
+		return cs.NamespaceResources(ctx, namespace)
+	})
+}
+
+func (k *kube) usage(ctx context.Context, clusterName string, summarize func(cs client.ClusterSet) (*client.ResourceSummary, error)) (*types.ResourceUsage, error) {
+	// 可分配总量根据 node 统计，申请量和限制量根据 pod 统计
+	cs, err := getClusterSet(ctx, clusterName, client.NodesResource, client.NamespacesResource, client.PodsResource)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := summarize(cs)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errors.NewError(err, http.StatusNotFound)
+		}
+		klog.Errorf("failed to summarize resources of cluster(%s): %v", clusterName, err)
+		return nil, errors.ErrServerInternal
+	}
+	return newResourceUsage(summary), nil
+}
+
+func newResourceUsage(s *client.ResourceSummary) *types.ResourceUsage {
+	usage := &types.ResourceUsage{
+		Cpu: types.ResourceUsageDetail{
+			RequestedPercent: util.Percent(s.Requested.Cpu, s.Capacity.Cpu),
+		},
+		Memory: types.ResourceUsageDetail{
+			RequestedPercent: util.Percent(s.Requested.Memory, s.Capacity.Memory),
+		},
+		MetricsAvailable: s.MetricsAvailable,
+	}
+	usage.Cpu.Requested, usage.Cpu.Limits = util.MultiCpuConvert(s.Requested.Cpu, s.Limits.Cpu)
+	usage.Memory.Requested, usage.Memory.Limits = util.MultiSizeConvert(s.Requested.Memory, s.Limits.Memory)
+	if s.MetricsAvailable {
+		usage.Cpu.Used, usage.Cpu.Capacity = util.MultiCpuConvert(s.Used.Cpu, s.Capacity.Cpu)
+		usage.Memory.Used, usage.Memory.Capacity = util.MultiSizeConvert(s.Used.Memory, s.Capacity.Memory)
+		usage.Cpu.UsedPercent = util.Percent(s.Used.Cpu, s.Capacity.Cpu)
+		usage.Memory.UsedPercent = util.Percent(s.Used.Memory, s.Capacity.Memory)
+	} else {
+		_, usage.Cpu.Capacity = util.MultiCpuConvert(0, s.Capacity.Cpu)
+		_, usage.Memory.Capacity = util.MultiSizeConvert(0, s.Capacity.Memory)
+	}
+	return usage
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"embed"
+	"fmt"
+	"net"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"text/template"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"kubevulpes/api/errors"
+	"kubevulpes/pkg/types"
+)
+
+// 安装包名称用于归档目录和下载的文件名，只保留安全的字符
+var unsafeNameRegexp = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var bundleTemplates = template.Must(template.New("bundle").
+	Option("missingkey=error").
+	Funcs(template.FuncMap{"quote": strconv.Quote}).
+	ParseFS(templateFS, "templates/*.tmpl"))
+
+const (
+	// haproxy 与 apiserver 部署在相同的 master 上，使用不同的端口转发请求
+	haproxyPort = 8443
+
+	addonNamespace  = "monitoring"
+	prometheusImage = "quay.io/prometheus/prometheus:v2.45.0"
+	grafanaImage    = "docker.io/grafana/grafana:10.4.2"
+
+	containerdSocket = "unix:///run/containerd/containerd.sock"
+	dockershimSocket = "unix:///var/run/dockershim.sock"
+)
+
+// Bundle 根据规划渲染出的安装包，打包为 tar.gz
+type Bundle struct {
+	Name string
+	Data []byte
+}
+
+// bundleNode 安装包中的节点信息，不包含主机的登陆凭证
+type bundleNode struct {
+	Name    string
+	Address string
+	Port    int
+	User    string
+}
+
+type bundleData struct {
+	Plan       *types.Plan
+	Masters    []bundleNode
+	Nodes      []bundleNode
+	InitMaster bundleNode
+
+	ControlPlaneEndpoint string
+	CertSANs             []string
+	CriSocket            string
+	HaproxyPort          int
+
+	AddonNamespace  string
+	Prometheus      bool
+	PrometheusImage string
+	GrafanaImage    string
+}
+
+// keepalivedData 每个 master 的 keepalived 配置，第一个 master 作为初始的 VIP 持有者
+type keepalivedData struct {
+	*bundleData
+	Node     bundleNode
+	State    string
+	Priority int
+}
+
+// Bundle 渲染规划的安装包，包括 kubeadm 配置、节点清单、haproxy/keepalived 配置和组件的部署清单
+func (p *plan) Bundle(ctx context.Context, planId int64) (*Bundle, error) {
+	t, err := p.Get(ctx, planId)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.Nodes) == 0 {
+		return nil, errors.NewError(fmt.Errorf("部署规划 %s 未配置节点", t.Name), http.StatusBadRequest)
+	}
+	// 渲染前重新校验，规划保存后引用的主机可能已经变化
+	setDefaults(t)
+	if err = p.validate(ctx, t); err != nil {
+		return nil, err
+	}
+
+	data, err := p.bundleData(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	files, err := renderBundle(data)
+	if err != nil {
+		klog.Errorf("failed to render bundle of plan(%d): %v", planId, err)
+		return nil, errors.ErrServerInternal
+	}
+	name := unsafeNameRegexp.ReplaceAllString(t.Name, "-")
+	archive, err := archiveBundle(name, t.GmtModified, files)
+	if err != nil {
+		klog.Errorf("failed to archive bundle of plan(%d): %v", planId, err)
+		return nil, errors.ErrServerInternal
+	}
+
+	return &Bundle{Name: name + ".tar.gz", Data: archive}, nil
+}
+
+func (p *plan) bundleData(ctx context.Context, t *types.Plan) (*bundleData, error) {
+	data := &bundleData{
+		Plan:            t,
+		HaproxyPort:     haproxyPort,
+		CriSocket:       containerdSocket,
+		AddonNamespace:  addonNamespace,
+		Prometheus:      t.Component.Prometheus != nil && t.Component.Prometheus.Enable,
+		PrometheusImage: prometheusImage,
+		GrafanaImage:    grafanaImage,
+	}
+	if t.Runtime.Runtime == "docker" {
+		data.CriSocket = dockershimSocket
+	}
+
+	for _, node := range t.Nodes {
+		host, err := p.factory.Host().Get(ctx, node.HostId)
+		if err != nil {
+			klog.Errorf("failed to get host(%d): %v", node.HostId, err)
+			return nil, errors.ErrServerInternal
+		}
+		if host == nil {
+			return nil, errors.NewError(fmt.Errorf("节点对应的主机(%d)不存在", node.HostId), http.StatusBadRequest)
+		}
+
+		n := bundleNode{Name: host.Name, Address: host.Address, Port: host.Port, User: host.User}
+		if node.Role == types.RoleMaster {
+			data.Masters = append(data.Masters, n)
+		} else {
+			data.Nodes = append(data.Nodes, n)
+		}
+	}
+	data.InitMaster = data.Masters[0]
+
+	// 高可用集群通过 VIP 访问 apiserver，开启 haproxy 时转发到 haproxy 的端口
+	endpoint, port := data.InitMaster.Address, t.Kubernetes.ApiPort
+	if len(t.Kubernetes.ApiServer) != 0 {
+		endpoint = t.Kubernetes.ApiServer
+	}
+	if haproxyEnabled(t) {
+		port = strconv.Itoa(haproxyPort)
+	}
+	data.ControlPlaneEndpoint = net.JoinHostPort(endpoint, port)
+
+	data.CertSANs = []string{endpoint}
+	for _, master := range data.Masters {
+		if master.Address != endpoint {
+			data.CertSANs = append(data.CertSANs, master.Address)
+		}
+	}
+	return data, nil
+}
+
+// renderBundle 按固定顺序渲染安装包中的文件，相同的规划渲染结果保持一致
+func renderBundle(data *bundleData) ([]bundleFile, error) {
+	var files []bundleFile
+	render := func(name string, tmpl string, data interface{}) error {
+		var buf bytes.Buffer
+		if err := bundleTemplates.ExecuteTemplate(&buf, tmpl, data); err != nil {
+			return fmt.Errorf("render %s: %v", name, err)
+		}
+		files = append(files, bundleFile{name: name, data: buf.Bytes()})
+		return nil
+	}
+
+	if err := render("kubeadm/kubeadm-config.yaml", "kubeadm-config.yaml.tmpl", data); err != nil {
+		return nil, err
+	}
+	if err := render("inventory.ini", "inventory.ini.tmpl", data); err != nil {
+		return nil, err
+	}
+	if haproxyEnabled(data.Plan) {
+		if err := render("haproxy/haproxy.cfg", "haproxy.cfg.tmpl", data); err != nil {
+			return nil, err
+		}
+		for i, master := range data.Masters {
+			kd := keepalivedData{bundleData: data, Node: master, State: "BACKUP", Priority: 100 - i}
+			if i == 0 {
+				kd.State = "MASTER"
+			}
+			if err := render(path.Join("keepalived", master.Name+".conf"), "keepalived.conf.tmpl", kd); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if data.Prometheus {
+		if err := render("addons/prometheus.yaml", "prometheus.yaml.tmpl", data); err != nil {
+			return nil, err
+		}
+	}
+	if grafana := data.Plan.Component.Grafana; grafana != nil && grafana.Enable {
+		if err := render("addons/grafana.yaml", "grafana.yaml.tmpl", data); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+type bundleFile struct {
+	name string
+	data []byte
+}
+
+// archiveBundle 将文件打包到以规划名称命名的目录下，修改时间使用规划的更新时间
+func archiveBundle(name string, modTime time.Time, files []bundleFile) ([]byte, error) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    path.Join(name, f.name),
+			Mode:    0644,
+			Size:    int64(len(f.data)),
+			ModTime: modTime,
+		}); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func haproxyEnabled(t *types.Plan) bool {
+	return t.Component.Haproxy != nil && t.Component.Haproxy.Enable
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"kubevulpes/pkg/db"
+	"kubevulpes/pkg/db/model"
+	"kubevulpes/pkg/types"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+type fakeFactory struct {
+	db.ShareDaoFactory
+	hosts map[int64]*model.Host
+}
+
+func (f *fakeFactory) Host() db.HostInterface { return &fakeHostDao{hosts: f.hosts} }
+
+type fakeHostDao struct {
+	db.HostInterface
+	hosts map[int64]*model.Host
+}
+
+func (f *fakeHostDao) Get(ctx context.Context, hostId int64) (*model.Host, error) {
+	return f.hosts[hostId], nil
+}
+
+func testHosts() map[int64]*model.Host {
+	hosts := make(map[int64]*model.Host)
+	for i, address := range []string{"192.168.1.11", "192.168.1.12", "192.168.1.13", "192.168.1.21", "192.168.1.22"} {
+		id := int64(i + 1)
+		hosts[id] = &model.Host{Name: "host-" + address[len(address)-2:], Address: address, Port: 22, User: "root"}
+	}
+	return hosts
+}
+
+func TestRenderBundle(t *testing.T) {
+	cases := []struct {
+		name string
+		plan types.Plan
+	}{
+		{
+			name: "single-master",
+			plan: types.Plan{
+				Name:       "single",
+				Kubernetes: types.KubernetesSpec{KubernetesVersion: "1.26.3"},
+				Nodes: types.PlanNodes{
+					{HostId: 1, Role: types.RoleMaster},
+					{HostId: 4, Role: types.RoleNode},
+				},
+			},
+		},
+		{
+			name: "ha-docker-addons",
+			plan: types.Plan{
+				Name: "ha",
+				Kubernetes: types.KubernetesSpec{
+					ApiServer:         "192.168.1.100",
+					KubernetesVersion: "1.26.3",
+					EnableHA:          true,
+				},
+				Network: types.NetworkSpec{NetworkInterface: "ens33", Cni: "calico"},
+				Runtime: types.RuntimeSpec{Runtime: "docker"},
+				Component: types.ComponentSpec{
+					Prometheus: &types.Prometheus{Enable: true},
+					Grafana:    &types.Grafana{Enable: true, GrafanaAdminUser: "admin", GrafanaAdminPassword: "admin"},
+					Haproxy:    &types.Haproxy{Enable: true, KeepalivedVirtualRouterId: "51"},
+				},
+				Nodes: types.PlanNodes{
+					{HostId: 1, Role: types.RoleMaster},
+					{HostId: 2, Role: types.RoleMaster},
+					{HostId: 3, Role: types.RoleMaster},
+					{HostId: 4, Role: types.RoleNode},
+					{HostId: 5, Role: types.RoleNode},
+				},
+			},
+		},
+	}
+
+	p := &plan{factory: &fakeFactory{hosts: testHosts()}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			setDefaults(&tc.plan)
+			data, err := p.bundleData(context.TODO(), &tc.plan)
+			if err != nil {
+				t.Fatalf("failed to build bundle data: %v", err)
+			}
+			files, err := renderBundle(data)
+			if err != nil {
+				t.Fatalf("failed to render bundle: %v", err)
+			}
+
+			dir := filepath.Join("testdata", tc.name)
+			if *update {
+				if err = os.RemoveAll(dir); err != nil {
+					t.Fatal(err)
+				}
+			}
+			rendered := make(map[string]bool)
+			for _, file := range files {
+				golden := filepath.Join(dir, filepath.FromSlash(file.name)+".golden")
+				rendered[golden] = true
+				if *update {
+					if err = os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+						t.Fatal(err)
+					}
+					if err = os.WriteFile(golden, file.data, 0644); err != nil {
+						t.Fatal(err)
+					}
+					continue
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatalf("failed to read golden file, run with -update to create it: %v", err)
+				}
+				if string(want) != string(file.data) {
+					t.Errorf("%s mismatch, run with -update if the change is expected\n--- want\n%s\n--- got\n%s", file.name, want, file.data)
+				}
+			}
+
+			// 模板不再渲染的文件也需要从 golden 中清理掉
+			err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+				if !rendered[path] {
+					t.Errorf("stale golden file %s, run with -update to remove it", path)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
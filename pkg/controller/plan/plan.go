@@ -0,0 +1,303 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"k8s.io/klog/v2"
+
+	"kubevulpes/api/errors"
+	"kubevulpes/cmd/app/config"
+	"kubevulpes/pkg/db"
+	"kubevulpes/pkg/db/model"
+	"kubevulpes/pkg/types"
+	utilerrors "kubevulpes/pkg/util/errors"
+)
+
+type PlanGetter interface {
+	Plan() Interface
+}
+
+type Interface interface {
+	Create(ctx context.Context, req *types.CreatePlanRequest) (*types.Plan, error)
+	Update(ctx context.Context, planId int64, req *types.UpdatePlanRequest) error
+	Delete(ctx context.Context, planId int64) error
+	Get(ctx context.Context, planId int64) (*types.Plan, error)
+	List(ctx context.Context, listOptions *types.ListOptions) (*types.PageResponse, error)
+
+	// LinkCluster 关联规划部署出的集群，clusterId 为 0 时解除关联
+	LinkCluster(ctx context.Context, planId int64, req *types.LinkPlanClusterRequest) error
+	// Bundle 渲染规划的安装包
+	Bundle(ctx context.Context, planId int64) (*Bundle, error)
+}
+
+type plan struct {
+	cc      config.Config
+	factory db.ShareDaoFactory
+}
+
+func (p *plan) Create(ctx context.Context, req *types.CreatePlanRequest) (*types.Plan, error) {
+	t := &types.Plan{
+		Name:        req.Name,
+		Description: req.Description,
+		Kubernetes:  req.Kubernetes,
+		Network:     req.Network,
+		Runtime:     req.Runtime,
+		Component:   req.Component,
+		Nodes:       req.Nodes,
+	}
+	setDefaults(t)
+	if err := p.validate(ctx, t); err != nil {
+		return nil, err
+	}
+
+	object, err := type2Model(t)
+	if err != nil {
+		klog.Errorf("failed to build plan(%s): %v", req.Name, err)
+		return nil, errors.ErrServerInternal
+	}
+	if object, err = p.factory.Plan().Create(ctx, object); err != nil {
+		if utilerrors.IsUniqueConstraintError(err) {
+			return nil, errors.ErrPlanExists
+		}
+		klog.Errorf("failed to create plan(%s): %v", req.Name, err)
+		return nil, errors.ErrServerInternal
+	}
+
+	return p.model2Type(object), nil
+}
+
+// Update 合并更新的字段后重新校验整个规划，避免局部修改破坏配置之间的约束
+func (p *plan) Update(ctx context.Context, planId int64, req *types.UpdatePlanRequest) error {
+	object, err := p.factory.Plan().Get(ctx, planId)
+	if err != nil {
+		klog.Errorf("failed to get plan(%d): %v", planId, err)
+		return errors.ErrServerInternal
+	}
+	if object == nil {
+		return errors.ErrPlanNotFound
+	}
+
+	t := p.model2Type(object)
+	if req.Name != nil {
+		t.Name = *req.Name
+	}
+	if req.Description != nil {
+		t.Description = *req.Description
+	}
+	if req.Kubernetes != nil {
+		t.Kubernetes = *req.Kubernetes
+	}
+	if req.Network != nil {
+		t.Network = *req.Network
+	}
+	if req.Runtime != nil {
+		t.Runtime = *req.Runtime
+	}
+	if req.Component != nil {
+		t.Component = *req.Component
+	}
+	if req.Nodes != nil {
+		t.Nodes = *req.Nodes
+	}
+	setDefaults(t)
+	if err = p.validate(ctx, t); err != nil {
+		return err
+	}
+
+	updated, err := type2Model(t)
+	if err != nil {
+		klog.Errorf("failed to build plan(%d): %v", planId, err)
+		return errors.ErrServerInternal
+	}
+	if err = p.factory.Plan().Update(ctx, planId, *req.ResourceVersion, map[string]interface{}{
+		"name":        updated.Name,
+		"description": updated.Description,
+		"kubernetes":  updated.Kubernetes,
+		"network":     updated.Network,
+		"runtime":     updated.Runtime,
+		"component":   updated.Component,
+		"nodes":       updated.Nodes,
+	}); err != nil {
+		if utilerrors.IsUniqueConstraintError(err) {
+			return errors.ErrPlanExists
+		}
+		if utilerrors.IsNotUpdated(err) {
+			return errors.NewError(fmt.Errorf("部署规划已被修改，请刷新后重试"), http.StatusConflict)
+		}
+		klog.Errorf("failed to update plan(%d): %v", planId, err)
+		return errors.ErrServerInternal
+	}
+	return nil
+}
+
+func (p *plan) Delete(ctx context.Context, planId int64) error {
+	object, err := p.factory.Plan().Get(ctx, planId)
+	if err != nil {
+		klog.Errorf("failed to get plan(%d): %v", planId, err)
+		return errors.ErrServerInternal
+	}
+	if object == nil {
+		return errors.ErrPlanNotFound
+	}
+
+	if err = p.factory.Plan().Delete(ctx, planId); err != nil {
+		klog.Errorf("failed to delete plan(%d): %v", planId, err)
+		return errors.ErrServerInternal
+	}
+	return nil
+}
+
+func (p *plan) Get(ctx context.Context, planId int64) (*types.Plan, error) {
+	object, err := p.factory.Plan().Get(ctx, planId)
+	if err != nil {
+		klog.Errorf("failed to get plan(%d): %v", planId, err)
+		return nil, errors.ErrServerInternal
+	}
+	if object == nil {
+		return nil, errors.ErrPlanNotFound
+	}
+
+	return p.model2Type(object), nil
+}
+
+func (p *plan) List(ctx context.Context, listOptions *types.ListOptions) (*types.PageResponse, error) {
+	objects, total, err := p.factory.Plan().List(ctx, listOptions.BuildPageNation()...)
+	if err != nil {
+		klog.Errorf("failed to list plans: %v", err)
+		return nil, errors.ErrServerInternal
+	}
+
+	plans := make([]types.Plan, len(objects))
+	for i := range objects {
+		plans[i] = *p.model2Type(&objects[i])
+	}
+	return &types.PageResponse{
+		PageRequest: listOptions.PageRequest,
+		Total:       int(total),
+		Items:       plans,
+	}, nil
+}
+
+// LinkCluster 一个集群只能由一个规划部署，集群已关联其他规划时返回冲突
+func (p *plan) LinkCluster(ctx context.Context, planId int64, req *types.LinkPlanClusterRequest) error {
+	object, err := p.factory.Plan().Get(ctx, planId)
+	if err != nil {
+		klog.Errorf("failed to get plan(%d): %v", planId, err)
+		return errors.ErrServerInternal
+	}
+	if object == nil {
+		return errors.ErrPlanNotFound
+	}
+
+	clusterId := *req.ClusterId
+	if clusterId != 0 {
+		cluster, err := p.factory.Cluster().Get(ctx, clusterId)
+		if err != nil {
+			klog.Errorf("failed to get cluster(%d): %v", clusterId, err)
+			return errors.ErrServerInternal
+		}
+		if cluster == nil {
+			return errors.ErrClusterNotFound
+		}
+		if cluster.PlanId != 0 && cluster.PlanId != planId {
+			return errors.NewError(fmt.Errorf("集群 %s 已关联部署规划(%d)", cluster.Name, cluster.PlanId), http.StatusConflict)
+		}
+	}
+
+	if err = p.factory.Plan().LinkCluster(ctx, planId, clusterId); err != nil {
+		if utilerrors.IsNotUpdated(err) {
+			return errors.NewError(fmt.Errorf("集群(%d)已关联其他部署规划", clusterId), http.StatusConflict)
+		}
+		klog.Errorf("failed to link plan(%d) to cluster(%d): %v", planId, clusterId, err)
+		return errors.ErrServerInternal
+	}
+	return nil
+}
+
+func (p *plan) model2Type(o *model.Plan) *types.Plan {
+	t := &types.Plan{
+		VulpesMeta: types.VulpesMeta{
+			Id:              o.Id,
+			ResourceVersion: o.ResourceVersion,
+		},
+		TimeMeta: types.TimeMeta{
+			GmtCreate:   o.GmtCreate,
+			GmtModified: o.GmtModified,
+		},
+		Name:        o.Name,
+		Description: o.Description,
+		ClusterId:   o.ClusterId,
+	}
+
+	specs := []struct {
+		data      string
+		unmarshal func(string) error
+	}{
+		{o.Kubernetes, t.Kubernetes.Unmarshal},
+		{o.Network, t.Network.Unmarshal},
+		{o.Runtime, t.Runtime.Unmarshal},
+		{o.Component, t.Component.Unmarshal},
+		{o.Nodes, t.Nodes.Unmarshal},
+	}
+	for _, spec := range specs {
+		if len(spec.data) == 0 {
+			continue
+		}
+		if err := spec.unmarshal(spec.data); err != nil {
+			klog.Warningf("failed to unmarshal spec of plan(%d): %v", o.Id, err)
+		}
+	}
+	return t
+}
+
+// type2Model 将规划的各项配置序列化为 json 字符串
+func type2Model(t *types.Plan) (*model.Plan, error) {
+	object := &model.Plan{
+		Name:        t.Name,
+		Description: t.Description,
+		ClusterId:   t.ClusterId,
+	}
+
+	var err error
+	if object.Kubernetes, err = t.Kubernetes.Marshal(); err != nil {
+		return nil, err
+	}
+	if object.Network, err = t.Network.Marshal(); err != nil {
+		return nil, err
+	}
+	if object.Runtime, err = t.Runtime.Marshal(); err != nil {
+		return nil, err
+	}
+	if object.Component, err = t.Component.Marshal(); err != nil {
+		return nil, err
+	}
+	if object.Nodes, err = t.Nodes.Marshal(); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func NewPlan(cfg config.Config, f db.ShareDaoFactory) *plan {
+	return &plan{
+		cc:      cfg,
+		factory: f,
+	}
+}
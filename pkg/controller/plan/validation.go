@@ -0,0 +1,228 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/klog/v2"
+
+	"kubevulpes/api/errors"
+	"kubevulpes/pkg/types"
+)
+
+const (
+	defaultNetworkInterface = "eth0"
+	defaultCni              = "calico"
+	defaultPodNetwork       = "172.30.0.0/16"
+	defaultServiceNetwork   = "10.254.0.0/16"
+	defaultKubeProxy        = "iptables"
+	defaultRuntime          = "containerd"
+	defaultApiPort          = "6443"
+
+	// 高可用集群至少需要 3 个 master 才能容忍单节点故障
+	minHAMasters = 3
+)
+
+var (
+	// 支持部署的 kubernetes 次版本
+	minKubernetesVersion = utilversion.MustParseGeneric("v1.23.0")
+	maxKubernetesVersion = utilversion.MustParseGeneric("v1.30.0")
+	// 1.24 移除了 dockershim，之后的版本不再支持 docker 运行时
+	dockershimRemovedVersion = utilversion.MustParseGeneric("v1.24.0")
+
+	supportedCnis       = []string{"calico", "flannel", "cilium"}
+	supportedKubeProxys = []string{"iptables", "ipvs"}
+	supportedRuntimes   = []string{"containerd", "docker"}
+)
+
+// setDefaults 为未填写的配置设置默认值
+func setDefaults(t *types.Plan) {
+	if len(t.Kubernetes.ApiPort) == 0 {
+		t.Kubernetes.ApiPort = defaultApiPort
+	}
+	if len(t.Network.NetworkInterface) == 0 {
+		t.Network.NetworkInterface = defaultNetworkInterface
+	}
+	if len(t.Network.Cni) == 0 {
+		t.Network.Cni = defaultCni
+	}
+	if len(t.Network.PodNetwork) == 0 {
+		t.Network.PodNetwork = defaultPodNetwork
+	}
+	if len(t.Network.ServiceNetwork) == 0 {
+		t.Network.ServiceNetwork = defaultServiceNetwork
+	}
+	if len(t.Network.KubeProxy) == 0 {
+		t.Network.KubeProxy = defaultKubeProxy
+	}
+	if len(t.Runtime.Runtime) == 0 {
+		t.Runtime.Runtime = defaultRuntime
+	}
+}
+
+// validate 校验规划的各项配置，节点必须是已经录入的主机
+func (p *plan) validate(ctx context.Context, t *types.Plan) error {
+	version, err := validateKubernetes(&t.Kubernetes)
+	if err != nil {
+		return errors.NewError(err, http.StatusBadRequest)
+	}
+	if err = validateNetwork(&t.Network); err != nil {
+		return errors.NewError(err, http.StatusBadRequest)
+	}
+	if err = validateRuntime(&t.Runtime, version); err != nil {
+		return errors.NewError(err, http.StatusBadRequest)
+	}
+	if err = validateComponent(&t.Component, &t.Kubernetes); err != nil {
+		return errors.NewError(err, http.StatusBadRequest)
+	}
+	if err = validateNodes(t.Nodes, &t.Kubernetes); err != nil {
+		return errors.NewError(err, http.StatusBadRequest)
+	}
+
+	for _, node := range t.Nodes {
+		host, err := p.factory.Host().Get(ctx, node.HostId)
+		if err != nil {
+			klog.Errorf("failed to get host(%d): %v", node.HostId, err)
+			return errors.ErrServerInternal
+		}
+		if host == nil {
+			return errors.NewError(fmt.Errorf("节点对应的主机(%d)不存在", node.HostId), http.StatusBadRequest)
+		}
+	}
+	return nil
+}
+
+func validateKubernetes(ks *types.KubernetesSpec) (*utilversion.Version, error) {
+	if len(ks.KubernetesVersion) == 0 {
+		return nil, fmt.Errorf("kubernetes 版本不能为空")
+	}
+	version, err := utilversion.ParseSemantic(ks.KubernetesVersion)
+	if err != nil || !strings.HasPrefix(ks.KubernetesVersion, "v") {
+		return nil, fmt.Errorf("kubernetes 版本 %q 不合法，格式为 v1.x.y", ks.KubernetesVersion)
+	}
+	if version.LessThan(minKubernetesVersion) || version.AtLeast(maxKubernetesVersion.WithMinor(maxKubernetesVersion.Minor()+1)) {
+		return nil, fmt.Errorf("不支持的 kubernetes 版本 %s，支持 v%d.%d 到 v%d.%d",
+			ks.KubernetesVersion, minKubernetesVersion.Major(), minKubernetesVersion.Minor(), maxKubernetesVersion.Major(), maxKubernetesVersion.Minor())
+	}
+
+	port, err := strconv.Atoi(ks.ApiPort)
+	if err != nil || port < 1 || port > 65535 {
+		return nil, fmt.Errorf("apiserver 端口 %q 不合法", ks.ApiPort)
+	}
+	if ks.EnableHA && len(ks.ApiServer) == 0 {
+		return nil, fmt.Errorf("高可用集群需要指定 apiserver 的访问地址")
+	}
+	return version, nil
+}
+
+// validateNetwork pod 和 service 网段不能重叠，否则集群内的路由会冲突
+func validateNetwork(ns *types.NetworkSpec) error {
+	if !contains(supportedCnis, ns.Cni) {
+		return fmt.Errorf("不支持的网络插件 %s，支持 %s", ns.Cni, strings.Join(supportedCnis, ", "))
+	}
+	if !contains(supportedKubeProxys, ns.KubeProxy) {
+		return fmt.Errorf("不支持的 kube-proxy 模式 %s，支持 %s", ns.KubeProxy, strings.Join(supportedKubeProxys, ", "))
+	}
+
+	_, podNet, err := net.ParseCIDR(ns.PodNetwork)
+	if err != nil {
+		return fmt.Errorf("pod 网段 %q 不合法", ns.PodNetwork)
+	}
+	_, serviceNet, err := net.ParseCIDR(ns.ServiceNetwork)
+	if err != nil {
+		return fmt.Errorf("service 网段 %q 不合法", ns.ServiceNetwork)
+	}
+	if podNet.Contains(serviceNet.IP) || serviceNet.Contains(podNet.IP) {
+		return fmt.Errorf("pod 网段 %s 和 service 网段 %s 重叠", ns.PodNetwork, ns.ServiceNetwork)
+	}
+	return nil
+}
+
+func validateRuntime(rs *types.RuntimeSpec, version *utilversion.Version) error {
+	if !contains(supportedRuntimes, rs.Runtime) {
+		return fmt.Errorf("不支持的容器运行时 %s，支持 %s", rs.Runtime, strings.Join(supportedRuntimes, ", "))
+	}
+	if rs.Runtime == "docker" && version.AtLeast(dockershimRemovedVersion) {
+		return fmt.Errorf("kubernetes v%s 不支持 docker 运行时，请使用 containerd", version)
+	}
+	return nil
+}
+
+func validateComponent(cs *types.ComponentSpec, ks *types.KubernetesSpec) error {
+	if cs.Haproxy != nil && cs.Haproxy.Enable {
+		// haproxy 和 keepalived 为 master 提供 VIP，只用于高可用集群
+		if !ks.EnableHA {
+			return fmt.Errorf("haproxy 只能在高可用集群中开启")
+		}
+		if net.ParseIP(ks.ApiServer) == nil {
+			return fmt.Errorf("开启 haproxy 时 apiserver 地址 %q 必须是 VIP", ks.ApiServer)
+		}
+		id, err := strconv.Atoi(cs.Haproxy.KeepalivedVirtualRouterId)
+		if err != nil || id < 0 || id > 255 {
+			return fmt.Errorf("keepalived virtual router id %q 不合法，取值范围 0-255", cs.Haproxy.KeepalivedVirtualRouterId)
+		}
+	}
+	if cs.Grafana != nil && cs.Grafana.Enable {
+		if len(cs.Grafana.GrafanaAdminUser) == 0 || len(cs.Grafana.GrafanaAdminPassword) == 0 {
+			return fmt.Errorf("开启 grafana 时需要设置管理员用户名和密码")
+		}
+	}
+	return nil
+}
+
+// validateNodes 节点可以在部署前补充，为空时不检查 master 数量
+func validateNodes(nodes types.PlanNodes, ks *types.KubernetesSpec) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	masters := 0
+	hosts := make(map[int64]bool, len(nodes))
+	for _, node := range nodes {
+		if hosts[node.HostId] {
+			return fmt.Errorf("主机(%d)重复出现在节点列表中", node.HostId)
+		}
+		hosts[node.HostId] = true
+		if node.Role == types.RoleMaster {
+			masters++
+		}
+	}
+
+	if ks.EnableHA && masters < minHAMasters {
+		return fmt.Errorf("高可用集群至少需要 %d 个 master 节点，当前 %d 个", minHAMasters, masters)
+	}
+	if !ks.EnableHA && masters != 1 {
+		return fmt.Errorf("非高可用集群需要 1 个 master 节点，当前 %d 个", masters)
+	}
+	return nil
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
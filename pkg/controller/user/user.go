@@ -27,6 +27,7 @@ import (
 	"kubevulpes/api/httputils"
 	"kubevulpes/cmd/app/config"
 	"kubevulpes/pkg/client"
+	"kubevulpes/pkg/controller/auth"
 	"kubevulpes/pkg/db"
 	"kubevulpes/pkg/db/model"
 	"kubevulpes/pkg/types"
@@ -67,6 +68,7 @@ type user struct {
 	cc       config.Config
 	factory  db.ShareDaoFactory
 	enforcer *casbin.SyncedEnforcer
+	syncer   auth.AccessSyncer
 }
 
 func (u *user) Create(ctx context.Context, req *types.CreateUserRequest) error {
@@ -141,6 +143,8 @@ func (u *user) Delete(ctx context.Context, userId int64) error {
 
 	userIndexer.Delete(userId)
 	tokenIndexer.Delete(userId)
+	// 撤销用户在集群内的授权
+	go u.syncer.SyncUserAccess(context.Background(), userId)
 	return nil
 }
 
@@ -201,7 +205,7 @@ func (u *user) preResetPassword(ctx context.Context, userId int64, operatorId in
 		return err
 	}
 
-	if operator.Role != model.RoleRoot || operator.Role != model.RoleAdmin {
+	if operator.Role != model.RoleRoot && operator.Role != model.RoleAdmin {
 		return fmt.Errorf("非超级管理员，不允许重置用户密码")
 	}
 	return nil
@@ -344,10 +348,11 @@ func (u *user) model2Type(o *model.User) *types.User {
 	}
 }
 
-func NewUser(cfg config.Config, f db.ShareDaoFactory, e *casbin.SyncedEnforcer) *user {
+func NewUser(cfg config.Config, f db.ShareDaoFactory, e *casbin.SyncedEnforcer, s auth.AccessSyncer) *user {
 	return &user{
 		cc:       cfg,
 		factory:  f,
 		enforcer: e,
+		syncer:   s,
 	}
 }
@@ -0,0 +1,45 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// MarshalLabels 校验标签的格式，和 kubernetes 的标签规则保持一致
+func MarshalLabels(l map[string]string) (string, error) {
+	if len(l) == 0 {
+		return "", nil
+	}
+	for k, v := range l {
+		if errs := validation.IsQualifiedName(k); len(errs) != 0 {
+			return "", fmt.Errorf("标签 %q 不合法: %s", k, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(v); len(errs) != 0 {
+			return "", fmt.Errorf("标签 %q 的值 %q 不合法: %s", k, v, strings.Join(errs, "; "))
+		}
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
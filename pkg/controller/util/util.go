@@ -30,36 +30,98 @@ import (
 
 func MakeDbOptions(ctx context.Context) (opts []db.Options) {
 	exists, ids := httputils.GetIdRangeFromListReq(ctx)
-	if exists {
-		opts = append(opts, db.WithIDIn(ids...))
+	if !exists {
+		return
 	}
+	if ok, names := httputils.GetNameRangeFromListReq(ctx); ok && len(names) > 0 {
+		opts = append(opts, db.WithIDOrNameIn(ids, names))
+		return
+	}
+	opts = append(opts, db.WithIDIn(ids...))
 	return
 }
 
-func SetIdRangeContext(c *gin.Context, enforcer *casbin.SyncedEnforcer, user *model.User, obj string) error {
+// SetIdRangeContext 为列表接口设置可以返回的对象范围，返回 false 表示用户没有任何可以读取的对象
+// 平台对象按照 id 过滤，集群列表还包含 kubernetes 资源策略中的集群，kubernetes 资源按照命名空间过滤
+func SetIdRangeContext(c *gin.Context, enforcer *casbin.SyncedEnforcer, user *model.User, object httputils.RequestObject) (bool, error) {
 	bindings, err := GetGroupBindings(enforcer, QueryWithUserName(user.Name))
 	if err != nil {
-		return err
+		return false, err
 	}
 	if model.BindingToAdmin(bindings) {
 		// This user is an admin/root, it's unnecessary to set object IDs list to context.
-		return nil
+		return true, nil
 	}
 
-	ups, err := GetUserPolicies(enforcer, user, WithObjectType(model.ObjectType(obj)))
+	policies, err := GetUserAndGroupPolicies(enforcer, user, bindings)
 	if err != nil {
-		return err
+		return false, err
 	}
-	policies := make([]model.Policy, len(ups))
-	for i, up := range ups {
-		policies[i] = up
+	obj := model.ObjectType(object.Type)
+
+	if object.IsKube() {
+		// 命名空间内的列表已经按照命名空间鉴权
+		if len(object.SID) == 0 || len(object.Namespace) != 0 {
+			return false, nil
+		}
+		all, namespaces := model.GetNamespaceRangeFromPolicy(filterPolicies(policies, obj, object.SID))
+		if all {
+			return true, nil
+		}
+		httputils.SetNamespaceRangeContext(c, namespaces)
+		return len(namespaces) > 0, nil
 	}
-	if all, ids := model.GetIdRangeFromPolicy(policies); !all {
-		// Set a list of object IDs to context.
-		httputils.SetIdRangeContext(c, ids)
+
+	all, ids := model.GetIdRangeFromPolicy(filterPolicies(policies, obj, ""))
+	if all {
+		// If policy with all operation(*) exists, it's unnecessary to set object IDs list to context.
+		return true, nil
 	}
-	// If policy with all operation(*) exists, it's unnecessary to set object IDs list to context.
-	return nil
+	var names []string
+	if obj == model.ObjectCluster {
+		// 拥有集群内 kubernetes 资源权限的用户也可以看到对应的集群
+		if all, names = model.GetClusterRangeFromPolicy(policies); all {
+			return true, nil
+		}
+		httputils.SetNameRangeContext(c, names)
+	}
+	// Set a list of object IDs to context.
+	httputils.SetIdRangeContext(c, ids)
+	return len(ids)+len(names) > 0, nil
+}
+
+// GetUserAndGroupPolicies 返回用户自身以及 bindings 中用户组的策略
+func GetUserAndGroupPolicies(enforcer *casbin.SyncedEnforcer, user *model.User, bindings []model.GroupBinding) ([]model.Policy, error) {
+	ups, err := GetUserPolicies(enforcer, user)
+	if err != nil {
+		return nil, err
+	}
+	policies := make([]model.Policy, 0, len(ups))
+	for _, up := range ups {
+		policies = append(policies, up)
+	}
+	for _, binding := range bindings {
+		rp, err := enforcer.GetFilteredNamedPolicy("p", 0, binding.GetGroupName())
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range rp {
+			gp := model.GroupPolicy{}
+			_ = copy(gp[:], p)
+			policies = append(policies, gp)
+		}
+	}
+	return policies, nil
+}
+
+func filterPolicies(policies []model.Policy, obj model.ObjectType, sid string) []model.Policy {
+	var filtered []model.Policy
+	for _, policy := range policies {
+		if model.PolicyMatches(policy, obj, sid) {
+			filtered = append(filtered, policy)
+		}
+	}
+	return filtered
 }
 
 type BindingQueryCondition func(c *policyConditions) (index int)
@@ -81,7 +143,7 @@ func QueryWithUserName(name string) BindingQueryCondition {
 func GetGroupBindings(enforcer *casbin.SyncedEnforcer, conds ...BindingQueryCondition) ([]model.GroupBinding, error) {
 	var index int
 	pc := &policyConditions{
-		conds: [4]*string{},
+		conds: [5]*string{},
 	}
 	for _, cond := range conds {
 		i := cond(pc)
@@ -100,12 +162,12 @@ func GetGroupBindings(enforcer *casbin.SyncedEnforcer, conds ...BindingQueryCond
 }
 
 type policyConditions struct {
-	conds [4]*string
+	conds [5]*string
 }
 
 func newPolicyConditions(name string) *policyConditions {
 	return &policyConditions{
-		conds: [4]*string{&name},
+		conds: [5]*string{&name},
 	}
 }
 
@@ -142,6 +204,12 @@ func WithOperation(op model.Operation) PolicyCondition {
 	}
 }
 
+func WithNamespace(ns string) PolicyCondition {
+	return func(c *policyConditions) {
+		c.conds[4] = &ns
+	}
+}
+
 func GetUserPolicies(enforcer *casbin.SyncedEnforcer, user *model.User, conds ...PolicyCondition) ([]model.UserPolicy, error) {
 	pc := newPolicyConditions(user.Name)
 	for _, cond := range conds {
@@ -171,3 +239,28 @@ func GetGroupPolicy(enforcer *casbin.SyncedEnforcer, name string) (*model.GroupP
 	_ = copy(policy[:], rp[0])
 	return &policy, nil
 }
+
+// RemoveClusterPolicies 删除作用于集群的全部策略
+// 集群对象的策略按照集群 id 匹配，kubernetes 资源的策略按照集群名称匹配
+func RemoveClusterPolicies(enforcer *casbin.SyncedEnforcer, cluster *model.Cluster) error {
+	rules, err := enforcer.GetFilteredNamedPolicy("p", 1, model.ObjectCluster.String(), cluster.GetSID())
+	if err != nil {
+		return err
+	}
+	named, err := enforcer.GetFilteredNamedPolicy("p", 2, cluster.Name)
+	if err != nil {
+		return err
+	}
+	for _, rule := range named {
+		// e.g. ["foo", "deployments", "prod", "read", "*"]
+		if obj := model.ObjectType(rule[1]); model.IsKubeObject(obj) || obj == model.ObjectAll {
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	_, err = enforcer.RemoveNamedPolicies("p", rules)
+	return err
+}
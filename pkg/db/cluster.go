@@ -18,26 +18,45 @@ package db
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 
 	"kubevulpes/pkg/db/model"
+	"kubevulpes/pkg/util/crypto"
 	"kubevulpes/pkg/util/errors"
 )
 
+const kubeConfigColumn = "kube_config"
+
 type ClusterInterface interface {
 	Create(ctx context.Context, object *model.Cluster, fns ...func(*model.Cluster) error) (*model.Cluster, error)
 	Update(ctx context.Context, clusterId int64, resourceVersion int64, updates map[string]interface{}) error
+	InternalUpdate(ctx context.Context, clusterId int64, updates map[string]interface{}) error
+	UpdateStatus(ctx context.Context, object *model.Cluster, status model.ClusterStatus, message string) error
 	Delete(ctx context.Context, cluster *model.Cluster, fns ...func(*model.Cluster) error) error
 	Get(ctx context.Context, clusterId int64, opts ...Options) (*model.Cluster, error)
 	GetByName(ctx context.Context, name string) (*model.Cluster, error)
 	List(ctx context.Context, opts ...Options) ([]model.Cluster, int64, error)
+
+	// GetDeleted 获取已删除但尚未清理的集群
+	GetDeleted(ctx context.Context, clusterId int64) (*model.Cluster, error)
+	ListDeleted(ctx context.Context, opts ...Options) ([]model.Cluster, int64, error)
+	// Restore 恢复已删除的集群
+	Restore(ctx context.Context, clusterId int64) error
+	// Purge 彻底删除集群及其状态变更记录和用户 kubeConfig 签发记录
+	Purge(ctx context.Context, cluster *model.Cluster, fns ...func(*model.Cluster) error) error
+
+	ListStatusRecords(ctx context.Context, clusterId int64, opts ...Options) ([]model.ClusterStatusRecord, int64, error)
+
+	// EncryptLegacy 加密启用加密之前写入的明文 kubeConfig，包括已删除的集群，返回加密的集群数
+	EncryptLegacy(ctx context.Context) (int, error)
 }
 
 type cluster struct {
-	db *gorm.DB
+	db      *gorm.DB
+	keyring *crypto.Keyring
 }
 
 func (c *cluster) Create(ctx context.Context, object *model.Cluster, fns ...func(*model.Cluster) error) (*model.Cluster, error) {
@@ -45,6 +64,15 @@ func (c *cluster) Create(ctx context.Context, object *model.Cluster, fns ...func
 	object.GmtCreate = now
 	object.GmtModified = now
 
+	// kubeConfig 加密后入库，返回给调用方的对象仍然保持明文
+	kubeConfig := object.KubeConfig
+	encrypted, err := c.encrypt(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	object.KubeConfig = encrypted
+	defer func() { object.KubeConfig = kubeConfig }()
+
 	if err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(object).Error; err != nil {
 			return err
@@ -66,6 +94,9 @@ func (c *cluster) Update(ctx context.Context, clusterId int64, resourceVersion i
 	// 系统维护字段
 	updates["gmt_modified"] = time.Now()
 	updates["resource_version"] = resourceVersion + 1
+	if err := c.encryptUpdates(updates); err != nil {
+		return err
+	}
 
 	f := c.db.WithContext(ctx).Model(&model.Cluster{}).Where("id = ? and resource_version = ?", clusterId, resourceVersion).Updates(updates)
 	if f.Error != nil {
@@ -78,28 +109,122 @@ func (c *cluster) Update(ctx context.Context, clusterId int64, resourceVersion i
 	return nil
 }
 
-func (c *cluster) Delete(ctx context.Context, cid *model.Cluster, fns ...func(*model.Cluster) error) error {
-	// 仅当数据库支持回写功能时才能正常 可使用Scan(&deletedCluster) Scan(&deletedCluster) 用于将返回的记录扫描并存储到 deletedCluster 变量中。
-	if err := c.db.Clauses(clause.Returning{}).Where("id = ?", cid).Delete(&model.Cluster{}).Error; err != nil {
+// InternalUpdate 系统内部维护字段（如集群状态）的更新，不校验也不递增 resourceVersion，
+// 避免后台任务和用户的更新请求产生版本冲突
+func (c *cluster) InternalUpdate(ctx context.Context, clusterId int64, updates map[string]interface{}) error {
+	if err := c.encryptUpdates(updates); err != nil {
 		return err
 	}
+	// 已删除但尚未清理的集群也需要更新，例如重新加密 kubeConfig
+	return c.db.WithContext(ctx).Unscoped().Model(&model.Cluster{}).Where("id = ?", clusterId).Updates(updates).Error
+}
+
+// UpdateStatus 更新集群状态，状态发生变化时同时写入状态变更记录
+func (c *cluster) UpdateStatus(ctx context.Context, object *model.Cluster, status model.ClusterStatus, message string) error {
+	if object.ClusterStatus == status {
+		return nil
+	}
 
-	//return c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-	//	if err := tx.Delete(cluster).Error; err != nil {
-	//		return err
-	//	}
-	//
-	//	for _, fn := range fns {
-	//		if err := fn(cluster); err != nil {
-	//			return err
-	//		}
-	//	}
-	//	return nil
-	//})
+	if err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Cluster{}).Where("id = ?", object.Id).Update("status", status).Error; err != nil {
+			return err
+		}
 
+		now := time.Now()
+		record := &model.ClusterStatusRecord{
+			ClusterId: object.Id,
+			From:      object.ClusterStatus,
+			To:        status,
+			Message:   message,
+		}
+		record.GmtCreate = now
+		record.GmtModified = now
+		return tx.Create(record).Error
+	}); err != nil {
+		return err
+	}
+
+	object.ClusterStatus = status
 	return nil
 }
 
+// Delete 标记集群为已删除，集群数据保留到 Purge 时清理
+func (c *cluster) Delete(ctx context.Context, object *model.Cluster, fns ...func(*model.Cluster) error) error {
+	return c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(object).Error; err != nil {
+			return err
+		}
+
+		for _, fn := range fns {
+			if err := fn(object); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (c *cluster) GetDeleted(ctx context.Context, cid int64) (*model.Cluster, error) {
+	var object model.Cluster
+	if err := onlyDeleted(c.db.WithContext(ctx)).First(&object, cid).Error; err != nil {
+		if errors.IsRecordNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := c.decrypt(&object); err != nil {
+		return nil, err
+	}
+
+	return &object, nil
+}
+
+func (c *cluster) ListDeleted(ctx context.Context, opts ...Options) ([]model.Cluster, int64, error) {
+	return c.List(ctx, append([]Options{onlyDeleted}, opts...)...)
+}
+
+// onlyDeleted 只查询已删除的集群
+func onlyDeleted(tx *gorm.DB) *gorm.DB {
+	return tx.Unscoped().Where("gmt_deleted IS NOT NULL")
+}
+
+func (c *cluster) Restore(ctx context.Context, cid int64) error {
+	f := c.db.WithContext(ctx).Unscoped().Model(&model.Cluster{}).
+		Where("id = ? AND gmt_deleted IS NOT NULL", cid).
+		Updates(map[string]interface{}{
+			"gmt_deleted":  nil,
+			"gmt_modified": time.Now(),
+		})
+	if f.Error != nil {
+		return f.Error
+	}
+	if f.RowsAffected == 0 {
+		return errors.ErrRecordNotUpdate
+	}
+	return nil
+}
+
+func (c *cluster) Purge(ctx context.Context, object *model.Cluster, fns ...func(*model.Cluster) error) error {
+	return c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("cluster_id = ?", object.Id).Delete(&model.ClusterStatusRecord{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("cluster_id = ?", object.Id).Delete(&model.UserKubeConfig{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(object).Error; err != nil {
+			return err
+		}
+
+		for _, fn := range fns {
+			if err := fn(object); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (c *cluster) Get(ctx context.Context, cid int64, opts ...Options) (*model.Cluster, error) {
 	var object model.Cluster
 	tx := c.db.WithContext(ctx)
@@ -112,6 +237,9 @@ func (c *cluster) Get(ctx context.Context, cid int64, opts ...Options) (*model.C
 		}
 		return nil, err
 	}
+	if err := c.decrypt(&object); err != nil {
+		return nil, err
+	}
 
 	return &object, nil
 }
@@ -121,6 +249,9 @@ func (c *cluster) GetByName(ctx context.Context, name string) (*model.Cluster, e
 	if err := c.db.WithContext(ctx).Where("name = ?", name).First(&cluster).Error; err != nil {
 		return nil, err
 	}
+	if err := c.decrypt(&cluster); err != nil {
+		return nil, err
+	}
 	return &cluster, nil
 }
 
@@ -140,10 +271,56 @@ func (c *cluster) List(ctx context.Context, opts ...Options) ([]model.Cluster, i
 	if err := tx.Model(&model.Cluster{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
+	for i := range cs {
+		if err := c.decrypt(&cs[i]); err != nil {
+			return nil, 0, err
+		}
+	}
 
 	return cs, total, nil
 }
 
-func newCluster(db *gorm.DB) ClusterInterface {
-	return &cluster{db: db}
+func (c *cluster) ListStatusRecords(ctx context.Context, clusterId int64, opts ...Options) ([]model.ClusterStatusRecord, int64, error) {
+	var (
+		records []model.ClusterStatusRecord
+		total   int64
+	)
+
+	tx := c.db.WithContext(ctx).Where("cluster_id = ?", clusterId).Session(&gorm.Session{})
+	if err := tx.Model(&model.ClusterStatusRecord{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	if err := tx.Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, nil
+}
+
+func (c *cluster) EncryptLegacy(ctx context.Context) (int, error) {
+	return reencryptColumn(ctx, c.db, c.keyring, &model.Cluster{}, kubeConfigColumn, true)
+}
+
+func (c *cluster) encrypt(kubeConfig string) (string, error) {
+	return encryptField(c.keyring, kubeConfig)
+}
+
+func (c *cluster) encryptUpdates(updates map[string]interface{}) error {
+	return encryptColumns(c.keyring, updates, kubeConfigColumn)
+}
+
+func (c *cluster) decrypt(object *model.Cluster) error {
+	kubeConfig, err := decryptField(c.keyring, object.KubeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt kubeConfig of cluster(%d): %v", object.Id, err)
+	}
+	object.KubeConfig = kubeConfig
+	return nil
+}
+
+func newCluster(db *gorm.DB, keyring *crypto.Keyring) ClusterInterface {
+	return &cluster{db: db, keyring: keyring}
 }
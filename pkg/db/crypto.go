@@ -0,0 +1,108 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"kubevulpes/pkg/util/crypto"
+)
+
+// reencryptBatchSize 批量重新加密时每次读取的行数
+const reencryptBatchSize = 100
+
+// encryptField 加密敏感字段，空值和已经加密的数据原样返回
+func encryptField(keyring *crypto.Keyring, s string) (string, error) {
+	if len(s) == 0 || crypto.IsEncrypted(s) {
+		return s, nil
+	}
+	return keyring.Encrypt([]byte(s))
+}
+
+// decryptField 解密敏感字段，未加密的历史数据直接返回明文
+func decryptField(keyring *crypto.Keyring, s string) (string, error) {
+	if !crypto.IsEncrypted(s) {
+		return s, nil
+	}
+	data, err := keyring.Decrypt(s)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// encryptColumns 加密 updates 中指定的列
+func encryptColumns(keyring *crypto.Keyring, updates map[string]interface{}, columns ...string) error {
+	for _, column := range columns {
+		s, ok := updates[column].(string)
+		if !ok {
+			continue
+		}
+		encrypted, err := encryptField(keyring, s)
+		if err != nil {
+			return err
+		}
+		updates[column] = encrypted
+	}
+	return nil
+}
+
+// reencryptColumn 按照 id 分批读取模型对应表中的敏感列，使用主密钥加密后写回，返回写回的行数
+// legacyOnly 为 true 时只加密未加密的历史数据，否则全部使用主密钥重新加密，用于密钥轮转
+// 软删除的记录同样会被处理
+func reencryptColumn(ctx context.Context, db *gorm.DB, keyring *crypto.Keyring, object interface{}, column string, legacyOnly bool) (int, error) {
+	type row struct {
+		Id    int64
+		Value string
+	}
+
+	var (
+		lastId int64
+		total  int
+	)
+	for {
+		var rows []row
+		if err := db.WithContext(ctx).Unscoped().Model(object).Select("id, "+column+" AS value").
+			Where("id > ? AND "+column+" <> ''", lastId).Order("id").Limit(reencryptBatchSize).Find(&rows).Error; err != nil {
+			return total, err
+		}
+		if len(rows) == 0 {
+			return total, nil
+		}
+
+		for _, r := range rows {
+			lastId = r.Id
+			if legacyOnly && crypto.IsEncrypted(r.Value) {
+				continue
+			}
+			plaintext, err := decryptField(keyring, r.Value)
+			if err != nil {
+				return total, err
+			}
+			encrypted, err := keyring.Encrypt([]byte(plaintext))
+			if err != nil {
+				return total, err
+			}
+			if err = db.WithContext(ctx).Unscoped().Model(object).Where("id = ?", r.Id).UpdateColumn(column, encrypted).Error; err != nil {
+				return total, err
+			}
+			total++
+		}
+	}
+}
@@ -16,23 +16,35 @@ limitations under the License.
 
 package db
 
-import "gorm.io/gorm"
+import (
+	"gorm.io/gorm"
+
+	"kubevulpes/pkg/util/crypto"
+)
 
 type ShareDaoFactory interface {
 	User() UserInterface
 	Audit() AuditInterface
 	Cluster() ClusterInterface
+	Host() HostInterface
+	Plan() PlanInterface
+	UserKubeConfig() UserKubeConfigInterface
 }
 
 type shareDaoFactory struct {
-	db *gorm.DB
+	db      *gorm.DB
+	keyring *crypto.Keyring
 }
 
-func (f *shareDaoFactory) User() UserInterface       { return newUser(f.db) }
-func (f *shareDaoFactory) Audit() AuditInterface     { return newAudit(f.db) }
-func (f *shareDaoFactory) Cluster() ClusterInterface { return newCluster(f.db) }
+func (f *shareDaoFactory) User() UserInterface                     { return newUser(f.db) }
+func (f *shareDaoFactory) Audit() AuditInterface                   { return newAudit(f.db) }
+func (f *shareDaoFactory) Cluster() ClusterInterface               { return newCluster(f.db, f.keyring) }
+func (f *shareDaoFactory) Host() HostInterface                     { return newHost(f.db, f.keyring) }
+func (f *shareDaoFactory) Plan() PlanInterface                     { return newPlan(f.db) }
+func (f *shareDaoFactory) UserKubeConfig() UserKubeConfigInterface { return newUserKubeConfig(f.db) }
 
-func NewDaoFactory(db *gorm.DB, migrate bool) (ShareDaoFactory, error) {
+// NewDaoFactory 创建数据库访问接口，keyring 用于加密存储敏感字段（如 kubeConfig 和 SSH 认证信息）
+func NewDaoFactory(db *gorm.DB, migrate bool, keyring *crypto.Keyring) (ShareDaoFactory, error) {
 	if migrate {
 		// 自动创建指定模型的数据库表结构
 		if err := newMigrator(db).AutoMigrate(); err != nil {
@@ -41,6 +53,7 @@ func NewDaoFactory(db *gorm.DB, migrate bool) (ShareDaoFactory, error) {
 	}
 
 	return &shareDaoFactory{
-		db: db,
+		db:      db,
+		keyring: keyring,
 	}, nil
 }
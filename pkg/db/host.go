@@ -0,0 +1,230 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"kubevulpes/pkg/db/model"
+	"kubevulpes/pkg/util/crypto"
+	"kubevulpes/pkg/util/errors"
+)
+
+const (
+	passwordColumn   = "password"
+	privateKeyColumn = "private_key"
+	inputColumn      = "input"
+)
+
+type HostInterface interface {
+	Create(ctx context.Context, object *model.Host) (*model.Host, error)
+	Update(ctx context.Context, hostId int64, resourceVersion int64, updates map[string]interface{}) error
+	Delete(ctx context.Context, hostId int64) error
+	InternalUpdate(ctx context.Context, hostId int64, updates map[string]interface{}) error
+	Get(ctx context.Context, hostId int64) (*model.Host, error)
+	List(ctx context.Context, opts ...Options) ([]model.Host, int64, error)
+
+	CreateSession(ctx context.Context, object *model.HostSession) error
+	GetSession(ctx context.Context, hostId int64, sessionId int64) (*model.HostSession, error)
+	ListSessions(ctx context.Context, hostId int64, opts ...Options) ([]model.HostSession, int64, error)
+	// ReencryptSessions 使用主密钥重新加密全部会话的输入，返回重新加密的会话数
+	ReencryptSessions(ctx context.Context) (int, error)
+	// EncryptLegacySessions 加密启用加密之前录制的明文会话输入，返回加密的会话数
+	EncryptLegacySessions(ctx context.Context) (int, error)
+}
+
+type host struct {
+	db      *gorm.DB
+	keyring *crypto.Keyring
+}
+
+func (h *host) Create(ctx context.Context, object *model.Host) (*model.Host, error) {
+	now := time.Now()
+	object.GmtCreate = now
+	object.GmtModified = now
+
+	// 认证信息加密后入库，返回给调用方的对象仍然保持明文
+	password, privateKey := object.Password, object.PrivateKey
+	if err := h.encrypt(object); err != nil {
+		return nil, err
+	}
+	defer func() { object.Password, object.PrivateKey = password, privateKey }()
+
+	if err := h.db.WithContext(ctx).Create(object).Error; err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func (h *host) Update(ctx context.Context, hostId int64, resourceVersion int64, updates map[string]interface{}) error {
+	if err := encryptColumns(h.keyring, updates, passwordColumn, privateKeyColumn); err != nil {
+		return err
+	}
+	// 系统维护字段
+	updates["gmt_modified"] = time.Now()
+	updates["resource_version"] = resourceVersion + 1
+
+	f := h.db.WithContext(ctx).Model(&model.Host{}).Where("id = ? and resource_version = ?", hostId, resourceVersion).Updates(updates)
+	if f.Error != nil {
+		return f.Error
+	}
+	if f.RowsAffected == 0 {
+		return errors.ErrRecordNotUpdate
+	}
+	return nil
+}
+
+// Delete 删除主机，保留主机的终端会话记录用于审计
+func (h *host) Delete(ctx context.Context, hostId int64) error {
+	return h.db.WithContext(ctx).Where("id = ?", hostId).Delete(&model.Host{}).Error
+}
+
+// InternalUpdate 不校验也不递增 resourceVersion
+func (h *host) InternalUpdate(ctx context.Context, hostId int64, updates map[string]interface{}) error {
+	if err := encryptColumns(h.keyring, updates, passwordColumn, privateKeyColumn); err != nil {
+		return err
+	}
+	return h.db.WithContext(ctx).Model(&model.Host{}).Where("id = ?", hostId).Updates(updates).Error
+}
+
+func (h *host) Get(ctx context.Context, hostId int64) (*model.Host, error) {
+	var object model.Host
+	if err := h.db.WithContext(ctx).First(&object, hostId).Error; err != nil {
+		if errors.IsRecordNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := h.decrypt(&object); err != nil {
+		return nil, err
+	}
+	return &object, nil
+}
+
+func (h *host) List(ctx context.Context, opts ...Options) ([]model.Host, int64, error) {
+	var (
+		hosts []model.Host
+		total int64
+	)
+
+	tx := h.db.WithContext(ctx)
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	if err := tx.Find(&hosts).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := tx.Model(&model.Host{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	for i := range hosts {
+		if err := h.decrypt(&hosts[i]); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return hosts, total, nil
+}
+
+// CreateSession 会话的输入可能包含 sudo 密码等敏感信息，加密后入库
+func (h *host) CreateSession(ctx context.Context, object *model.HostSession) error {
+	now := time.Now()
+	object.GmtCreate = now
+	object.GmtModified = now
+
+	input := object.Input
+	var err error
+	if object.Input, err = encryptField(h.keyring, input); err != nil {
+		return err
+	}
+	defer func() { object.Input = input }()
+
+	return h.db.WithContext(ctx).Create(object).Error
+}
+
+func (h *host) GetSession(ctx context.Context, hostId int64, sessionId int64) (*model.HostSession, error) {
+	var object model.HostSession
+	if err := h.db.WithContext(ctx).Where("host_id = ?", hostId).First(&object, sessionId).Error; err != nil {
+		if errors.IsRecordNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	input, err := decryptField(h.keyring, object.Input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt input of host session(%d): %v", object.Id, err)
+	}
+	object.Input = input
+	return &object, nil
+}
+
+// ListSessions 列表中不返回会话的输入内容
+func (h *host) ListSessions(ctx context.Context, hostId int64, opts ...Options) ([]model.HostSession, int64, error) {
+	var (
+		sessions []model.HostSession
+		total    int64
+	)
+
+	tx := h.db.WithContext(ctx).Where("host_id = ?", hostId).Session(&gorm.Session{})
+	if err := tx.Model(&model.HostSession{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	if err := tx.Omit(inputColumn).Find(&sessions).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return sessions, total, nil
+}
+
+func (h *host) ReencryptSessions(ctx context.Context) (int, error) {
+	return reencryptColumn(ctx, h.db, h.keyring, &model.HostSession{}, inputColumn, false)
+}
+
+func (h *host) EncryptLegacySessions(ctx context.Context) (int, error) {
+	return reencryptColumn(ctx, h.db, h.keyring, &model.HostSession{}, inputColumn, true)
+}
+
+func (h *host) encrypt(object *model.Host) error {
+	var err error
+	if object.Password, err = encryptField(h.keyring, object.Password); err != nil {
+		return err
+	}
+	object.PrivateKey, err = encryptField(h.keyring, object.PrivateKey)
+	return err
+}
+
+func (h *host) decrypt(object *model.Host) error {
+	var err error
+	if object.Password, err = decryptField(h.keyring, object.Password); err != nil {
+		return fmt.Errorf("failed to decrypt password of host(%d): %v", object.Id, err)
+	}
+	if object.PrivateKey, err = decryptField(h.keyring, object.PrivateKey); err != nil {
+		return fmt.Errorf("failed to decrypt private key of host(%d): %v", object.Id, err)
+	}
+	return nil
+}
+
+func newHost(db *gorm.DB, keyring *crypto.Keyring) HostInterface {
+	return &host{db: db, keyring: keyring}
+}
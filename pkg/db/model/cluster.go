@@ -16,18 +16,43 @@ limitations under the License.
 
 package model
 
-import "kubevulpes/pkg/db/model/base"
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"kubevulpes/pkg/db/model/base"
+)
 
 type ClusterStatus uint8
 
 const (
-	ClusterStatusRunning   ClusterStatus = iota * 2 // 运行中
-	ClusterStatusError                              // 集群失联
-	ClusterStatusUnhealthy                          // 集群 node 节点不健康
+	ClusterStatusRunning    ClusterStatus = iota * 2 // 运行中
+	ClusterStatusError                               // 集群失联
+	ClusterStatusUnhealthy                           // 集群 node 节点不健康
+	ClusterStatusConnecting                          // 集群缓存构建中
+	ClusterStatusFailed                              // 集群缓存构建失败，后台持续重试
 )
 
+func (s ClusterStatus) String() string {
+	switch s {
+	case ClusterStatusRunning:
+		return "running"
+	case ClusterStatusError:
+		return "error"
+	case ClusterStatusUnhealthy:
+		return "unhealthy"
+	case ClusterStatusConnecting:
+		return "connecting"
+	case ClusterStatusFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
 func init() {
-	register(&Cluster{})
+	register(&Cluster{}, &ClusterStatusRecord{})
 }
 
 type Cluster struct {
@@ -37,7 +62,7 @@ type Cluster struct {
 	// 集群别名，可以重复，允许为中文
 	AliasName string `json:"alias_name"`
 
-	// 集群运行状态 0: 运行中 2: 集群失联 4: 所有的 node 不健康
+	// 集群运行状态 0: 运行中 2: 集群失联 4: 所有的 node 不健康 6: 缓存构建中 8: 缓存构建失败
 	ClusterStatus `gorm:"column:status;types:tinyint;not null" json:"status"`
 
 	// 集群的版本
@@ -50,9 +75,39 @@ type Cluster struct {
 	// 0: 关闭集群删除保护 1: 开启集群删除保护
 	Protected bool `json:"protected"`
 
-	// k8s kubeConfig todo 后续考虑对kubeconfig 进行加密
+	// k8s kubeConfig，使用信封加密存储，由 ClusterInterface 负责加解密
 	KubeConfig string `json:"kube_config"`
 
 	// 集群用途描述，可以为空
 	Description string `gorm:"type:text" json:"description"`
+
+	// 集群标签，json 格式存储，用于按照环境、地域等维度分组和筛选集群
+	Labels string `gorm:"column:labels;type:text" json:"labels"`
+
+	// 自建集群关联的部署规划，非自建集群为 0
+	PlanId int64 `gorm:"column:plan_id;index" json:"plan_id"`
+
+	// 集群缓存的资源列表，json 字符串，元素格式为 group/version/resource，为空时缓存默认资源
+	InformerResources string `gorm:"type:text" json:"informer_resources"`
+
+	// 集群删除时间，删除的集群在保留期内可以恢复，超过保留期后由后台任务彻底清理
+	GmtDeleted gorm.DeletedAt `gorm:"column:gmt_deleted;index" json:"gmt_deleted"`
+}
+
+// ClusterStatusRecord 集群状态变更记录，用于展示集群何时失联或恢复
+type ClusterStatusRecord struct {
+	base.Model
+
+	ClusterId int64         `gorm:"column:cluster_id;index;not null" json:"cluster_id"`
+	From      ClusterStatus `gorm:"column:from_status;types:tinyint;not null" json:"from"`
+	To        ClusterStatus `gorm:"column:to_status;types:tinyint;not null" json:"to"`
+	Message   string        `gorm:"type:text" json:"message"` // 状态变更原因
+}
+
+func (r *ClusterStatusRecord) TableName() string {
+	return "cluster_status_records"
+}
+
+func (r *ClusterStatusRecord) String() string {
+	return fmt.Sprintf("cluster(%d) status changed from %s to %s", r.ClusterId, r.From, r.To)
 }
@@ -0,0 +1,92 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"kubevulpes/pkg/db/model/base"
+)
+
+func init() {
+	register(&Host{}, &HostSession{})
+}
+
+type HostStatus uint8
+
+const (
+	HostStatusUnknown HostStatus = iota // 未检查
+	HostStatusOnline                    // 最近一次检查可以登陆
+	HostStatusOffline                   // 最近一次检查无法登陆
+)
+
+func (s HostStatus) String() string {
+	switch s {
+	case HostStatusOnline:
+		return "online"
+	case HostStatusOffline:
+		return "offline"
+	default:
+		return "unknown"
+	}
+}
+
+// Host 可以通过 SSH 登陆的机器
+type Host struct {
+	base.Model
+	Name    string `gorm:"column:name;types:varchar(128);not null;unique" json:"name"`
+	Address string `gorm:"column:address;types:varchar(128);not null" json:"address"`
+	Port    int    `gorm:"column:port;not null;default:22" json:"port"`
+
+	// SSH 认证方式 password: 密码认证 key: 私钥认证
+	AuthType string `gorm:"column:auth_type;types:varchar(32);not null" json:"auth_type"`
+	User     string `gorm:"column:user;types:varchar(128);not null" json:"user"`
+	// 密码和私钥使用信封加密后存储
+	Password   string `gorm:"column:password;types:text" json:"-"`
+	PrivateKey string `gorm:"column:private_key;types:text" json:"-"`
+	// 第一次检查成功时记录的 host key，authorized_keys 格式
+	HostKey string `gorm:"column:host_key;types:text" json:"-"`
+
+	// 主机在自建集群中的角色 master 或者 node
+	Role   string `gorm:"column:role;types:varchar(32);not null;default:node" json:"role"`
+	Labels string `gorm:"column:labels;types:text" json:"labels"` // 标签，json 格式存储
+
+	// 最近一次检查的结果，Facts 为采集到的系统信息，json 格式存储
+	Status        HostStatus `gorm:"column:status;not null;default:0" json:"status"`
+	Message       string     `gorm:"column:message;types:text" json:"message"`
+	Facts         string     `gorm:"column:facts;types:text" json:"facts"`
+	LastCheckTime *time.Time `gorm:"column:last_check_time" json:"last_check_time"`
+}
+
+func (h *Host) TableName() string {
+	return "hosts"
+}
+
+// HostSession 节点 SSH 终端的会话录制，通过 RequestId 关联同一次请求的审计记录
+type HostSession struct {
+	base.Model
+	HostId    int64     `gorm:"column:host_id;index;not null" json:"host_id"`
+	RequestId string    `gorm:"column:request_id;types:varchar(32);index" json:"request_id"`
+	Operator  string    `gorm:"types:varchar(255)" json:"operator"`
+	StartTime time.Time `gorm:"column:start_time" json:"start_time"`
+	EndTime   time.Time `gorm:"column:end_time" json:"end_time"`
+	Input     string    `gorm:"types:text" json:"input"` // 会话中用户的全部输入，可能包含密码，加密后存储
+}
+
+func (s *HostSession) TableName() string {
+	return "host_sessions"
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import "kubevulpes/pkg/db/model/base"
+
+func init() {
+	register(&Plan{})
+}
+
+// Plan 自建集群的部署规划，各项配置以 json 字符串存储，结构定义在 types 中
+type Plan struct {
+	base.Model
+	Name        string `gorm:"column:name;types:varchar(128);not null;unique" json:"name"`
+	Description string `gorm:"type:text" json:"description"`
+
+	// 根据规划部署出的集群，未关联时为 0
+	ClusterId int64 `gorm:"column:cluster_id;index" json:"cluster_id"`
+
+	Kubernetes string `gorm:"type:text" json:"kubernetes"`
+	Network    string `gorm:"type:text" json:"network"`
+	Runtime    string `gorm:"type:text" json:"runtime"`
+	Component  string `gorm:"type:text" json:"component"`
+	Nodes      string `gorm:"type:text" json:"nodes"` // 部署的节点及其角色
+}
+
+func (p *Plan) TableName() string {
+	return "plans"
+}
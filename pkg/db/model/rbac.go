@@ -32,6 +32,7 @@ const (
 	ReadWriteGroup       = "readwrite"
 	ReadWriteUpdateGroup = "readwriteupdate"
 	SidAll               = "*"
+	NamespaceAll         = "*"
 )
 
 type Operation string
@@ -41,6 +42,7 @@ const (
 	OpCreate Operation = "create"
 	OpUpdate Operation = "update"
 	OpDelete Operation = "delete"
+	OpExec   Operation = "exec" // 进入容器终端
 	OpAll    Operation = "*"
 )
 
@@ -74,6 +76,7 @@ var OperationMap = map[Operation]struct{}{
 	OpCreate: {},
 	OpUpdate: {},
 	OpDelete: {},
+	OpExec:   {},
 	OpAll:    {},
 }
 
@@ -82,8 +85,22 @@ type ObjectType string
 const (
 	ObjectUser    ObjectType = "users"
 	ObjectCluster ObjectType = "clusters"
+	ObjectHost    ObjectType = "hosts"
+	ObjectPlan    ObjectType = "plans"
 	ObjectAuth    ObjectType = "auth"
 	ObjectAll     ObjectType = "*"
+
+	// kubernetes 资源，鉴权时 sid 为集群名称，策略可以限定命名空间
+	// 未列出的资源（例如 CRD）使用资源的复数名称
+	ObjectNamespace   ObjectType = "namespaces"
+	ObjectNode        ObjectType = "nodes"
+	ObjectEvent       ObjectType = "events"
+	ObjectDeployment  ObjectType = "deployments"
+	ObjectStatefulSet ObjectType = "statefulsets"
+	ObjectDaemonSet   ObjectType = "daemonsets"
+	ObjectCronJob     ObjectType = "cronjobs"
+	ObjectJob         ObjectType = "jobs"
+	ObjectPod         ObjectType = "pods"
 )
 
 func (o ObjectType) String() string {
@@ -93,8 +110,36 @@ func (o ObjectType) String() string {
 var ObjectTypeMap = map[ObjectType]struct{}{
 	ObjectUser:    {},
 	ObjectCluster: {},
+	ObjectHost:    {},
+	ObjectPlan:    {},
 	//ObjectAuth:    {},
 	ObjectAll: {},
+
+	ObjectNamespace:   {},
+	ObjectNode:        {},
+	ObjectEvent:       {},
+	ObjectDeployment:  {},
+	ObjectStatefulSet: {},
+	ObjectDaemonSet:   {},
+	ObjectCronJob:     {},
+	ObjectJob:         {},
+	ObjectPod:         {},
+}
+
+// vulpesObjects 平台自身的对象，sid 为对象的 id，其余对象都是 kubernetes 资源
+var vulpesObjects = map[ObjectType]struct{}{
+	ObjectUser:    {},
+	ObjectCluster: {},
+	ObjectHost:    {},
+	ObjectPlan:    {},
+	ObjectAuth:    {},
+	ObjectAll:     {},
+}
+
+// IsKubeObject 判断对象是否为 kubernetes 资源，kubernetes 资源的策略可以限定命名空间
+func IsKubeObject(obj ObjectType) bool {
+	_, ok := vulpesObjects[obj]
+	return !ok
 }
 
 // TODO:
@@ -102,12 +147,13 @@ type RBACInterface interface{}
 
 // Casbin RBAC model
 // ref: https://github.com/casbin/casbin/blob/master/examples/rbac_with_domains_model.conf
+// ns 为 kubernetes 资源所在的命名空间，平台对象和集群级别的资源请求时 ns 为空
 const RBACModel = `
 [request_definition]
-r = sub, obj, id, op
+r = sub, obj, id, op, ns
 
 [policy_definition]
-p = sub, obj, id, op
+p = sub, obj, id, op, ns
 
 [role_definition]
 g = _, _
@@ -116,7 +162,7 @@ g = _, _
 e = some(where (p.eft == allow))
 
 [matchers]
-m = g(r.sub, p.sub) && keyMatch2(r.obj, p.obj) && keyMatch2(r.id, p.id) && keyMatch2(r.op, p.op)`
+m = g(r.sub, p.sub) && keyMatch2(r.obj, p.obj) && keyMatch2(r.id, p.id) && keyMatch2(r.op, p.op) && keyMatch2(r.ns, p.ns)`
 
 // TODO:
 type CasbinRBACImpl struct{}
@@ -126,13 +172,13 @@ type Policy interface {
 }
 
 // UserPolicy is a RBAC policy for user.
-// e.g. ["foo", "clusters", "*", "read"]
-type UserPolicy [4]string
+// e.g. ["foo", "clusters", "*", "read", "*"]
+type UserPolicy [5]string
 
 // NewUserPolicy returns a policy slice for user.
-// e.g. ["foo", "clusters", "*", "read"]: foo is a user name
-func NewUserPolicy(userName string, obj ObjectType, sid string, op Operation) UserPolicy {
-	return UserPolicy{userName, obj.String(), sid, op.String()}
+// e.g. ["foo", "deployments", "prod", "update", "team-a"]: foo is a user name
+func NewUserPolicy(userName string, obj ObjectType, sid string, op Operation, ns string) UserPolicy {
+	return UserPolicy{userName, obj.String(), sid, op.String(), ns}
 }
 
 func (p UserPolicy) Raw() []string {
@@ -155,14 +201,18 @@ func (p UserPolicy) GetOperation() Operation {
 	return Operation(p[3])
 }
 
+func (p UserPolicy) GetNamespace() string {
+	return p[4]
+}
+
 // GroupPolicy is a RBAC policy for group.
-// e.g. ["master", "clusters", "*", "*"]
-type GroupPolicy [4]string
+// e.g. ["master", "clusters", "*", "*", "*"]
+type GroupPolicy [5]string
 
 // NewGroupPolicy returns a policy slice for group.
-// e.g. ["master", "clusters", "*", "*"]: master is a group name
-func NewGroupPolicy(groupName string, obj ObjectType, sid string, op Operation) GroupPolicy {
-	return GroupPolicy{groupName, obj.String(), sid, op.String()}
+// e.g. ["master", "clusters", "*", "*", "*"]: master is a group name
+func NewGroupPolicy(groupName string, obj ObjectType, sid string, op Operation, ns string) GroupPolicy {
+	return GroupPolicy{groupName, obj.String(), sid, op.String(), ns}
 }
 
 func (p GroupPolicy) Raw() []string {
@@ -185,6 +235,10 @@ func (p GroupPolicy) GetOperation() Operation {
 	return Operation(p[3])
 }
 
+func (p GroupPolicy) GetNamespace() string {
+	return p[4]
+}
+
 // GroupBinding binds a user to a group.
 // e.g. ["foo", "master"]: user foo belongs to group master
 type GroupBinding [2]string
@@ -209,10 +263,10 @@ func (p GroupBinding) GetGroupName() string {
 // AdminPolicy is the specific policy for admin/root user.
 // \b(read|write|update)\b
 var (
-	AdminPolicy           = NewGroupPolicy(AdminGroup, ObjectAll, SidAll, OpAll)
-	ReadOnlyPolicy        = NewGroupPolicy(ReadOnlyGroup, ObjectAll, SidAll, buildOperation(OpRead))
-	ReadWritePolicy       = NewGroupPolicy(ReadWriteGroup, ObjectAll, SidAll, buildOperation(OpRead, OpCreate))
-	ReadWriteUpdatePolicy = NewGroupPolicy(ReadWriteUpdateGroup, ObjectAll, SidAll, buildOperation(OpRead, OpCreate, OpUpdate))
+	AdminPolicy           = NewGroupPolicy(AdminGroup, ObjectAll, SidAll, OpAll, NamespaceAll)
+	ReadOnlyPolicy        = NewGroupPolicy(ReadOnlyGroup, ObjectAll, SidAll, buildOperation(OpRead), NamespaceAll)
+	ReadWritePolicy       = NewGroupPolicy(ReadWriteGroup, ObjectAll, SidAll, buildOperation(OpRead, OpCreate), NamespaceAll)
+	ReadWriteUpdatePolicy = NewGroupPolicy(ReadWriteUpdateGroup, ObjectAll, SidAll, buildOperation(OpRead, OpCreate, OpUpdate), NamespaceAll)
 )
 
 // IsAdminPolicy returns true if the policy is the admin policy.
@@ -238,22 +292,42 @@ func BindingToAdmin(policies []GroupBinding) bool {
 // NewPolicyFromModels returns a policy slice.
 // e.g. ["foo", "clusters", "*", "*"]
 func NewPolicyFromModels(user *User, obj ObjectType, model base.Model, op Operation) Policy {
-	return NewUserPolicy(user.Name, obj, model.GetSID(), op)
+	return NewUserPolicy(user.Name, obj, model.GetSID(), op, NamespaceAll)
+}
+
+// policyFields 返回策略的 obj, sid, op, ns，历史策略没有 ns 时不限定命名空间
+func policyFields(policy Policy) (obj ObjectType, sid string, op string, ns string, ok bool) {
+	if _, isBinding := policy.(GroupBinding); isBinding {
+		return
+	}
+	raw := policy.Raw() // e.g. ["foo", "deployments", "prod", "read", "team-a"]
+	if len(raw) < 4 {
+		return
+	}
+	ns = NamespaceAll
+	if len(raw) > 4 {
+		ns = raw[4]
+	}
+	return ObjectType(raw[1]), raw[2], raw[3], ns, true
+}
+
+// canRead 判断策略的操作是否包含读取
+func canRead(op string) bool {
+	return KeyMatch(OpRead.String(), op)
 }
 
 // NOTE: GetIdRangeFromPolicy is only used for listing API request.
 // GetIdRangeFromPolicy returns true and an empty list when policy with all operation(*) are allowed exists,
 // otherwise it returns false and a list of object IDs.
+// 调用方需要先按照对象类型过滤策略
 func GetIdRangeFromPolicy(policies []Policy) (all bool, ids []int64) {
 	ids = make([]int64, 0)
 	for _, policy := range policies {
-		if _, ok := policy.(GroupBinding); ok {
+		_, sid, op, _, ok := policyFields(policy)
+		if !ok || !canRead(op) {
 			continue
 		}
 
-		raw := policy.Raw() // e.g. ["foo", "clusters", "*", "read"]
-		sid := raw[2]
-		// TODO: GetIdRangeFromPolicy 当前不准确
 		switch sid {
 		case "":
 			continue
@@ -262,11 +336,6 @@ func GetIdRangeFromPolicy(policies []Policy) (all bool, ids []int64) {
 			return true, []int64{}
 		}
 
-		// operation
-		if !(raw[3] == OpRead.String() || raw[3] == OpAll.String()) {
-			continue
-		}
-
 		id, err := strconv.ParseInt(sid, 10, 64)
 		if err != nil {
 			// invalid sid
@@ -277,6 +346,68 @@ func GetIdRangeFromPolicy(policies []Policy) (all bool, ids []int64) {
 	return false, utils.DeduplicateIntSlice(ids)
 }
 
+// GetNamespaceRangeFromPolicy 返回策略允许读取的命名空间，all 为 true 时不限定命名空间
+// 调用方需要先按照对象类型和集群过滤策略
+func GetNamespaceRangeFromPolicy(policies []Policy) (all bool, namespaces []string) {
+	seen := make(map[string]struct{})
+	for _, policy := range policies {
+		_, _, op, ns, ok := policyFields(policy)
+		if !ok || !canRead(op) || len(ns) == 0 {
+			continue
+		}
+		if ns == NamespaceAll {
+			return true, nil
+		}
+		if _, ok = seen[ns]; !ok {
+			seen[ns] = struct{}{}
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return false, namespaces
+}
+
+// GetClusterRangeFromPolicy 返回 kubernetes 资源策略中允许读取的集群名称，用于过滤集群列表
+// all 为 true 时可以读取全部集群
+func GetClusterRangeFromPolicy(policies []Policy) (all bool, names []string) {
+	seen := make(map[string]struct{})
+	for _, policy := range policies {
+		obj, sid, op, _, ok := policyFields(policy)
+		if !ok || !canRead(op) || len(sid) == 0 || (!IsKubeObject(obj) && obj != ObjectAll) {
+			continue
+		}
+		if sid == SidAll {
+			return true, nil
+		}
+		// 通配对象的策略中，数字 sid 表示集群 id，集群名称不允许为纯数字
+		if _, err := strconv.ParseInt(sid, 10, 64); err == nil && obj == ObjectAll {
+			continue
+		}
+		if _, ok = seen[sid]; !ok {
+			seen[sid] = struct{}{}
+			names = append(names, sid)
+		}
+	}
+	return false, names
+}
+
+// PolicyMatches 判断策略是否作用于对象类型和 sid，用于计算列表接口的范围
+func PolicyMatches(policy Policy, obj ObjectType, sid string) bool {
+	pobj, psid, _, _, ok := policyFields(policy)
+	if !ok || !KeyMatch(obj.String(), pobj.String()) {
+		return false
+	}
+	return len(sid) == 0 || KeyMatch(sid, psid)
+}
+
+// KeyMatch 判断 key 是否匹配策略中的 pattern，* 匹配任意值，其余按照正则表达式完整匹配
+func KeyMatch(key, pattern string) bool {
+	if pattern == "*" {
+		return true
+	}
+	matched, _ := regexp.MatchString("^(?:"+pattern+")$", key)
+	return matched
+}
+
 // 自定义 keyMatch 函数，支持正则表达式匹配
 func CustomKeyMatch(parameters ...interface{}) (interface{}, error) {
 	if len(parameters) != 2 {
@@ -290,6 +421,5 @@ func CustomKeyMatch(parameters ...interface{}) (interface{}, error) {
 		return false, fmt.Errorf("parameters must be strings")
 	}
 
-	matched, _ := regexp.MatchString(key2, key1)
-	return matched, nil
+	return KeyMatch(key1, key2), nil
 }
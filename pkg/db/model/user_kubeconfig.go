@@ -0,0 +1,40 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"kubevulpes/pkg/db/model/base"
+)
+
+func init() {
+	register(&UserKubeConfig{})
+}
+
+// UserKubeConfig 记录为用户签发过 kubeConfig 或者代理过请求的集群，用户权限变更时同步集群内的 RBAC 对象
+type UserKubeConfig struct {
+	base.Model
+
+	UserId    int64     `gorm:"column:user_id;uniqueIndex:idx_user_cluster;not null" json:"user_id"`
+	ClusterId int64     `gorm:"column:cluster_id;uniqueIndex:idx_user_cluster;index;not null" json:"cluster_id"`
+	GmtExpire time.Time `gorm:"column:gmt_expire" json:"gmt_expire"` // 最近一次签发的 token 过期时间
+}
+
+func (k *UserKubeConfig) TableName() string {
+	return "user_kubeconfigs"
+}
@@ -17,9 +17,14 @@ limitations under the License.
 package db
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 )
 
 type Options func(*gorm.DB) *gorm.DB
@@ -48,6 +53,12 @@ func WithCreatedBefore(t time.Time) Options {
 	}
 }
 
+func WithDeletedBefore(t time.Time) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("gmt_deleted < ?", t)
+	}
+}
+
 func WithLimit(limit int) Options {
 	return func(tx *gorm.DB) *gorm.DB {
 		if limit == 0 {
@@ -65,8 +76,79 @@ func WithIDIn(ids ...int64) Options {
 	}
 }
 
+// WithIDOrNameIn 按照 id 或者名称过滤，用于同时按照 id 和名称授权的对象
+func WithIDOrNameIn(ids []int64, names []string) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		// e.g. `WHERE (id IN (1, 2) OR name IN ('foo'))`
+		return tx.Where("id IN ? OR name IN ?", ids, names)
+	}
+}
+
 func WithPagination(page, pageSize int) Options {
 	return func(tx *gorm.DB) *gorm.DB {
 		return tx.Offset((page - 1) * pageSize).Limit(page * pageSize)
 	}
 }
+
+func WithName(name string) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", name)
+	}
+}
+
+// WithNameLike 按照名称子串搜索
+func WithNameLike(name string) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		// e.g. `WHERE name LIKE '%foo%'`
+		return tx.Where("name LIKE ?", "%"+escapeLike(name)+"%")
+	}
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// labelsColumn 标签以 json 格式存储在 labels 字段，未设置标签时字段为空字符串
+const labelsColumn = "COALESCE(NULLIF(labels, ''), '{}')"
+
+// WithLabelSelector 按照 kubernetes 风格的标签选择器过滤，e.g. `env=prod,region in (a,b)`
+func WithLabelSelector(selector labels.Selector) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		requirements, _ := selector.Requirements()
+		for _, r := range requirements {
+			query, args := labelCondition(r)
+			tx = tx.Where(query, args...)
+		}
+		return tx
+	}
+}
+
+// labelCondition 将标签选择器的单个条件转换为 sql 条件，语义和 kubernetes 保持一致：
+// 不存在的标签满足 != 和 notin 条件
+func labelCondition(r labels.Requirement) (string, []interface{}) {
+	// 标签的 key 已经通过选择器的格式校验，不会包含双引号
+	path := fmt.Sprintf(`$."%s"`, r.Key())
+	exists := fmt.Sprintf("JSON_CONTAINS_PATH(%s, 'one', ?)", labelsColumn)
+	value := fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, ?))", labelsColumn)
+	values := r.Values().List()
+
+	switch r.Operator() {
+	case selection.Exists:
+		return exists, []interface{}{path}
+	case selection.DoesNotExist:
+		return "NOT " + exists, []interface{}{path}
+	case selection.Equals, selection.DoubleEquals, selection.In:
+		return value + " IN ?", []interface{}{path, values}
+	case selection.NotEquals, selection.NotIn:
+		return fmt.Sprintf("(NOT %s OR %s NOT IN ?)", exists, value), []interface{}{path, path, values}
+	case selection.GreaterThan, selection.LessThan:
+		op := ">"
+		if r.Operator() == selection.LessThan {
+			op = "<"
+		}
+		// 选择器解析时已经校验比较的值为整数，只有值为整数的标签参与比较
+		n, _ := strconv.ParseInt(values[0], 10, 64)
+		return fmt.Sprintf("(%s REGEXP '^-{0,1}[0-9]+$' AND CAST(%s AS SIGNED) %s ?)", value, value, op), []interface{}{path, path, n}
+	}
+	return "1 = 0", nil
+}
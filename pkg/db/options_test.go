@@ -0,0 +1,91 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"kubevulpes/pkg/db/model"
+)
+
+func dryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to open dry run db: %v", err)
+	}
+	return db
+}
+
+func TestWithLabelSelector(t *testing.T) {
+	const (
+		column     = "COALESCE(NULLIF(labels, ''), '{}')"
+		notDeleted = "`clusters`.`gmt_deleted` IS NULL"
+	)
+	exists := func(key string) string {
+		return "JSON_CONTAINS_PATH(" + column + `, 'one', '$."` + key + `"')`
+	}
+	value := func(key string) string {
+		return "JSON_UNQUOTE(JSON_EXTRACT(" + column + `, '$."` + key + `"'))`
+	}
+	where := func(conditions ...string) string {
+		return "SELECT * FROM `clusters` WHERE " + strings.Join(append(conditions, notDeleted), " AND ")
+	}
+
+	cases := []struct {
+		name     string
+		selector string
+		expected string
+	}{
+		{name: "everything", selector: "", expected: where()},
+		{name: "equals", selector: "env=prod", expected: where(value("env") + " IN ('prod')")},
+		{name: "double equals", selector: "env==prod", expected: where(value("env") + " IN ('prod')")},
+		{name: "in", selector: "region in (b,a)", expected: where(value("region") + " IN ('a','b')")},
+		// 不存在的标签同样满足 != 和 notin
+		{name: "not equals", selector: "env!=prod", expected: where("((NOT " + exists("env") + " OR " + value("env") + " NOT IN ('prod')))")},
+		{name: "not in", selector: "region notin (a,b)", expected: where("((NOT " + exists("region") + " OR " + value("region") + " NOT IN ('a','b')))")},
+		{name: "exists", selector: "env", expected: where(exists("env"))},
+		{name: "does not exist", selector: "!env", expected: where("NOT " + exists("env"))},
+		{name: "greater than", selector: "replicas>3", expected: where("((" + value("replicas") + " REGEXP '^-{0,1}[0-9]+$' AND CAST(" + value("replicas") + " AS SIGNED) > 3))")},
+		{name: "less than", selector: "replicas<10", expected: where("((" + value("replicas") + " REGEXP '^-{0,1}[0-9]+$' AND CAST(" + value("replicas") + " AS SIGNED) < 10))")},
+		{name: "multiple requirements", selector: "env=prod,!deprecated", expected: where("NOT "+exists("deprecated"), value("env")+" IN ('prod')")},
+		{name: "dotted key", selector: "app.kubernetes.io/name=vulpes", expected: where(value("app.kubernetes.io/name") + " IN ('vulpes')")},
+	}
+
+	db := dryRunDB(t)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			selector, err := labels.Parse(tc.selector)
+			if err != nil {
+				t.Fatalf("failed to parse selector %q: %v", tc.selector, err)
+			}
+			sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				return WithLabelSelector(selector)(tx).Find(&[]model.Cluster{})
+			})
+			if sql != tc.expected {
+				t.Errorf("expected sql\n%s\ngot\n%s", tc.expected, sql)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"kubevulpes/pkg/db/model"
+	"kubevulpes/pkg/util/errors"
+)
+
+type PlanInterface interface {
+	Create(ctx context.Context, object *model.Plan) (*model.Plan, error)
+	Update(ctx context.Context, planId int64, resourceVersion int64, updates map[string]interface{}) error
+	Delete(ctx context.Context, planId int64) error
+	Get(ctx context.Context, planId int64) (*model.Plan, error)
+	List(ctx context.Context, opts ...Options) ([]model.Plan, int64, error)
+
+	// LinkCluster 关联规划和部署出的集群，clusterId 为 0 时解除关联
+	LinkCluster(ctx context.Context, planId int64, clusterId int64) error
+	// UnlinkCluster 集群删除后解除与规划的关联
+	UnlinkCluster(ctx context.Context, clusterId int64) error
+}
+
+type plan struct {
+	db *gorm.DB
+}
+
+func (p *plan) Create(ctx context.Context, object *model.Plan) (*model.Plan, error) {
+	now := time.Now()
+	object.GmtCreate = now
+	object.GmtModified = now
+
+	if err := p.db.WithContext(ctx).Create(object).Error; err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func (p *plan) Update(ctx context.Context, planId int64, resourceVersion int64, updates map[string]interface{}) error {
+	// 系统维护字段
+	updates["gmt_modified"] = time.Now()
+	updates["resource_version"] = resourceVersion + 1
+
+	f := p.db.WithContext(ctx).Model(&model.Plan{}).Where("id = ? and resource_version = ?", planId, resourceVersion).Updates(updates)
+	if f.Error != nil {
+		return f.Error
+	}
+	if f.RowsAffected == 0 {
+		return errors.ErrRecordNotUpdate
+	}
+	return nil
+}
+
+// Delete 删除规划，同时解除已关联集群的引用
+func (p *plan) Delete(ctx context.Context, planId int64) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Cluster{}).Where("plan_id = ?", planId).Update("plan_id", 0).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", planId).Delete(&model.Plan{}).Error
+	})
+}
+
+func (p *plan) Get(ctx context.Context, planId int64) (*model.Plan, error) {
+	var object model.Plan
+	if err := p.db.WithContext(ctx).First(&object, planId).Error; err != nil {
+		if errors.IsRecordNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &object, nil
+}
+
+func (p *plan) List(ctx context.Context, opts ...Options) ([]model.Plan, int64, error) {
+	var (
+		plans []model.Plan
+		total int64
+	)
+
+	tx := p.db.WithContext(ctx)
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	if err := tx.Find(&plans).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := tx.Model(&model.Plan{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return plans, total, nil
+}
+
+// LinkCluster 规划和集群一一对应，集群已关联其他规划时返回 ErrRecordNotUpdate
+func (p *plan) LinkCluster(ctx context.Context, planId int64, clusterId int64) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Cluster{}).Where("plan_id = ?", planId).Update("plan_id", 0).Error; err != nil {
+			return err
+		}
+		if clusterId != 0 {
+			f := tx.Model(&model.Cluster{}).Where("id = ? and plan_id = 0", clusterId).Update("plan_id", planId)
+			if f.Error != nil {
+				return f.Error
+			}
+			if f.RowsAffected == 0 {
+				return errors.ErrRecordNotUpdate
+			}
+		}
+
+		return tx.Model(&model.Plan{}).Where("id = ?", planId).Updates(map[string]interface{}{
+			"cluster_id":   clusterId,
+			"gmt_modified": time.Now(),
+		}).Error
+	})
+}
+
+func (p *plan) UnlinkCluster(ctx context.Context, clusterId int64) error {
+	return p.db.WithContext(ctx).Model(&model.Plan{}).Where("cluster_id = ?", clusterId).Update("cluster_id", 0).Error
+}
+
+func newPlan(db *gorm.DB) PlanInterface {
+	return &plan{db: db}
+}
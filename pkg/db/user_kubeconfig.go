@@ -0,0 +1,68 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"kubevulpes/pkg/db/model"
+)
+
+type UserKubeConfigInterface interface {
+	// Save 记录同步过授权的集群，同一个用户和集群只保留一条记录，过期时间取最晚的一次
+	Save(ctx context.Context, object *model.UserKubeConfig) error
+	ListByUser(ctx context.Context, userId int64) ([]model.UserKubeConfig, error)
+	Delete(ctx context.Context, userId int64, clusterId int64) error
+}
+
+type userKubeConfig struct {
+	db *gorm.DB
+}
+
+func (u *userKubeConfig) Save(ctx context.Context, object *model.UserKubeConfig) error {
+	now := time.Now()
+	object.GmtCreate = now
+	object.GmtModified = now
+
+	return u.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "cluster_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"gmt_modified": now,
+			"gmt_expire":   gorm.Expr("GREATEST(gmt_expire, VALUES(gmt_expire))"),
+		}),
+	}).Create(object).Error
+}
+
+func (u *userKubeConfig) ListByUser(ctx context.Context, userId int64) ([]model.UserKubeConfig, error) {
+	var objects []model.UserKubeConfig
+	if err := u.db.WithContext(ctx).Where("user_id = ?", userId).Find(&objects).Error; err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+func (u *userKubeConfig) Delete(ctx context.Context, userId int64, clusterId int64) error {
+	return u.db.WithContext(ctx).Where("user_id = ? AND cluster_id = ?", userId, clusterId).Delete(&model.UserKubeConfig{}).Error
+}
+
+func newUserKubeConfig(db *gorm.DB) UserKubeConfigInterface {
+	return &userKubeConfig{db: db}
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/klog/v2"
+
+	"kubevulpes/pkg/client"
+	"kubevulpes/pkg/db"
+	"kubevulpes/pkg/db/model"
+	"kubevulpes/pkg/types"
+	logutil "kubevulpes/pkg/util/log"
+)
+
+const (
+	DefaultProbeSchedule = "* * * * *" // 每分钟执行一次
+	DefaultProbeTimeout  = 10 * time.Second
+)
+
+// ClusterProber 定期检查已缓存集群的健康状态，并回写集群版本、节点就绪情况和运行状态
+type ClusterProber struct {
+	cfg   ProberOptions
+	dao   db.ShareDaoFactory
+	cache *client.Cache
+}
+
+type ProberOptions struct {
+	Schedule string
+	Timeout  time.Duration
+}
+
+func DefaultProberOptions() ProberOptions {
+	return ProberOptions{
+		Schedule: DefaultProbeSchedule,
+		Timeout:  DefaultProbeTimeout,
+	}
+}
+
+func NewClusterProber(cfg ProberOptions, dao db.ShareDaoFactory, cache *client.Cache) *ClusterProber {
+	return &ClusterProber{
+		cfg:   cfg,
+		dao:   dao,
+		cache: cache,
+	}
+}
+
+func (cp *ClusterProber) Name() string {
+	return "cluster-prober"
+}
+
+func (cp *ClusterProber) CronSpec() string {
+	return cp.cfg.Schedule
+}
+
+func (cp *ClusterProber) LogLevel() logutil.LogLevel {
+	return logutil.DebugLevel
+}
+
+func (cp *ClusterProber) Do(ctx *JobContext) error {
+	var (
+		wg      sync.WaitGroup
+		lock    sync.Mutex
+		changed []string
+	)
+
+	clusterSets := cp.cache.List()
+	for name, cs := range clusterSets {
+		wg.Add(1)
+		go func(name string, cs client.ClusterSet) {
+			defer wg.Done()
+
+			ok, err := cp.probe(ctx, name, cs)
+			if err != nil {
+				klog.Errorf("failed to probe cluster(%s): %v", name, err)
+				return
+			}
+			if ok {
+				lock.Lock()
+				changed = append(changed, name)
+				lock.Unlock()
+			}
+		}(name, cs)
+	}
+	wg.Wait()
+
+	ctx.WithLogFields(map[string]interface{}{
+		"clusters_probed":  len(clusterSets),
+		"clusters_changed": changed,
+	})
+	return nil
+}
+
+// probe 检查单个集群，返回集群状态是否发生变化
+func (cp *ClusterProber) probe(ctx context.Context, name string, cs client.ClusterSet) (bool, error) {
+	object, err := cp.dao.Cluster().GetByName(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	from := object.ClusterStatus
+
+	info, err := cp.serverVersion(ctx, cs)
+	if err != nil {
+		if err = cp.dao.Cluster().UpdateStatus(ctx, object, model.ClusterStatusError, err.Error()); err != nil {
+			return false, err
+		}
+		return from != object.ClusterStatus, nil
+	}
+
+	nodes, err := cp.nodeReadiness(ctx, cs)
+	if err != nil {
+		return false, err
+	}
+	nodesData, err := nodes.Marshal()
+	if err != nil {
+		return false, err
+	}
+	if err = cp.dao.Cluster().InternalUpdate(ctx, object.Id, map[string]interface{}{
+		"kubernetes_version": info.GitVersion,
+		"nodes":              nodesData,
+	}); err != nil {
+		return false, err
+	}
+
+	status, message := model.ClusterStatusRunning, "集群运行正常"
+	if len(nodes.Ready) == 0 {
+		status, message = model.ClusterStatusUnhealthy, fmt.Sprintf("集群没有就绪的节点，未就绪节点: %v", nodes.NotReady)
+	}
+	if err = cp.dao.Cluster().UpdateStatus(ctx, object, status, message); err != nil {
+		return false, err
+	}
+	return from != object.ClusterStatus, nil
+}
+
+// serverVersion 通过 discovery 接口获取集群版本，同时用于检查 apiserver 的连通性
+func (cp *ClusterProber) serverVersion(ctx context.Context, cs client.ClusterSet) (*version.Info, error) {
+	body, err := cs.Client.Discovery().RESTClient().Get().
+		AbsPath("/version").
+		Timeout(cp.cfg.Timeout).
+		Do(ctx).
+		Raw()
+	if err != nil {
+		return nil, err
+	}
+
+	var info version.Info
+	if err = json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("unable to parse the server version: %v", err)
+	}
+	return &info, nil
+}
+
+// nodeReadiness 统计节点的就绪情况
+// 节点缓存已启动时从缓存读取，否则直接请求 apiserver，避免探测任务启动或者保活集群的缓存
+func (cp *ClusterProber) nodeReadiness(ctx context.Context, cs client.ClusterSet) (*types.KubeNode, error) {
+	nodes, err := cp.listNodes(ctx, cs)
+	if err != nil {
+		return nil, err
+	}
+
+	kn := &types.KubeNode{
+		Ready:    make([]string, 0),
+		NotReady: make([]string, 0),
+	}
+	for _, node := range nodes {
+		if isNodeReady(node) {
+			kn.Ready = append(kn.Ready, node.Name)
+		} else {
+			kn.NotReady = append(kn.NotReady, node.Name)
+		}
+	}
+	return kn, nil
+}
+
+func (cp *ClusterProber) listNodes(ctx context.Context, cs client.ClusterSet) ([]*v1.Node, error) {
+	if cs.Informer.HasSynced(client.NodesResource) {
+		if lister, err := cs.Informer.NodesLister(); err == nil {
+			return lister.List(labels.Everything())
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cp.cfg.Timeout)
+	defer cancel()
+	list, err := cs.Client.CoreV1().Nodes().List(ctx, metav1.ListOptions{ResourceVersion: "0"})
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]*v1.Node, len(list.Items))
+	for i := range list.Items {
+		nodes[i] = &list.Items[i]
+	}
+	return nodes, nil
+}
+
+func isNodeReady(node *v1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == v1.NodeReady {
+			return cond.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
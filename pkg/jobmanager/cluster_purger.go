@@ -0,0 +1,111 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobmanager
+
+import (
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"k8s.io/klog/v2"
+
+	ctrlutil "kubevulpes/pkg/controller/util"
+	"kubevulpes/pkg/db"
+	"kubevulpes/pkg/db/model"
+	logutil "kubevulpes/pkg/util/log"
+)
+
+const (
+	DefaultPurgeSchedule    = "0 3 * * *" // 每天 3 点执行
+	DefaultClusterRetention = 7 * 24 * time.Hour
+)
+
+// ClusterPurger 彻底清理超过保留期的已删除集群，同时删除集群的状态记录、部署规划的关联和权限策略
+type ClusterPurger struct {
+	cfg      PurgerOptions
+	dao      db.ShareDaoFactory
+	enforcer *casbin.SyncedEnforcer
+}
+
+type PurgerOptions struct {
+	Schedule  string
+	Retention time.Duration
+}
+
+func DefaultPurgerOptions() PurgerOptions {
+	return PurgerOptions{
+		Schedule:  DefaultPurgeSchedule,
+		Retention: DefaultClusterRetention,
+	}
+}
+
+func NewClusterPurger(cfg PurgerOptions, dao db.ShareDaoFactory, enforcer *casbin.SyncedEnforcer) *ClusterPurger {
+	return &ClusterPurger{
+		cfg:      cfg,
+		dao:      dao,
+		enforcer: enforcer,
+	}
+}
+
+func (cp *ClusterPurger) Name() string {
+	return "cluster-purger"
+}
+
+func (cp *ClusterPurger) CronSpec() string {
+	return cp.cfg.Schedule
+}
+
+func (cp *ClusterPurger) LogLevel() logutil.LogLevel {
+	return logutil.InfoLevel
+}
+
+func (cp *ClusterPurger) Do(ctx *JobContext) error {
+	before := time.Now().Add(-cp.cfg.Retention)
+	objects, _, err := cp.dao.Cluster().ListDeleted(ctx, db.WithDeletedBefore(before))
+	if err != nil {
+		return err
+	}
+
+	purged := make([]string, 0)
+	for i := range objects {
+		object := &objects[i]
+		if err = cp.dao.Cluster().Purge(ctx, object, cp.cleanup(ctx)); err != nil {
+			klog.Errorf("failed to purge cluster(%d): %v", object.Id, err)
+			continue
+		}
+		purged = append(purged, object.Name)
+	}
+
+	ctx.WithLogFields(map[string]interface{}{
+		"retention":       cp.cfg.Retention.String(),
+		"deadline":        before,
+		"clusters_purged": purged,
+	})
+	return nil
+}
+
+// cleanup 清理集群关联的数据，失败时集群数据不会被删除，下次执行时重试
+func (cp *ClusterPurger) cleanup(ctx *JobContext) func(*model.Cluster) error {
+	return func(object *model.Cluster) error {
+		if err := cp.dao.Plan().UnlinkCluster(ctx, object.Id); err != nil {
+			return err
+		}
+		if cp.enforcer == nil {
+			return nil
+		}
+		return ctrlutil.RemoveClusterPolicies(cp.enforcer, object)
+	}
+}
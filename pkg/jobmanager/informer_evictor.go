@@ -0,0 +1,83 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobmanager
+
+import (
+	"time"
+
+	"kubevulpes/pkg/client"
+	logutil "kubevulpes/pkg/util/log"
+)
+
+const (
+	DefaultEvictSchedule = "*/5 * * * *" // 每 5 分钟执行一次
+	DefaultIdleTimeout   = 30 * time.Minute
+)
+
+// InformerEvictor 停止长时间未被访问的集群缓存，释放内存，再次访问时重新同步
+type InformerEvictor struct {
+	cfg   EvictorOptions
+	cache *client.Cache
+}
+
+type EvictorOptions struct {
+	Schedule    string
+	IdleTimeout time.Duration
+}
+
+func DefaultEvictorOptions() EvictorOptions {
+	return EvictorOptions{
+		Schedule:    DefaultEvictSchedule,
+		IdleTimeout: DefaultIdleTimeout,
+	}
+}
+
+func NewInformerEvictor(cfg EvictorOptions, cache *client.Cache) *InformerEvictor {
+	return &InformerEvictor{
+		cfg:   cfg,
+		cache: cache,
+	}
+}
+
+func (ie *InformerEvictor) Name() string {
+	return "informer-evictor"
+}
+
+func (ie *InformerEvictor) CronSpec() string {
+	return ie.cfg.Schedule
+}
+
+func (ie *InformerEvictor) LogLevel() logutil.LogLevel {
+	return logutil.DebugLevel
+}
+
+func (ie *InformerEvictor) Do(ctx *JobContext) error {
+	evicted := make([]string, 0)
+	for name, cs := range ie.cache.List() {
+		if !cs.Informer.Running() || time.Since(cs.Informer.LastAccess()) < ie.cfg.IdleTimeout {
+			continue
+		}
+		cs.Informer.Cancel()
+		evicted = append(evicted, name)
+	}
+
+	ctx.WithLogFields(map[string]interface{}{
+		"idle_timeout":     ie.cfg.IdleTimeout.String(),
+		"clusters_evicted": evicted,
+	})
+	return nil
+}
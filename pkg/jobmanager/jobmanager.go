@@ -0,0 +1,72 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobmanager
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/robfig/cron/v3"
+
+	logutil "kubevulpes/pkg/util/log"
+)
+
+// Job 定时任务，按照 CronSpec 周期执行
+type Job interface {
+	Name() string
+	CronSpec() string
+	LogLevel() logutil.LogLevel
+	Do(ctx *JobContext) error
+}
+
+type JobManager struct {
+	cron    *cron.Cron
+	logOpts *logutil.LogOptions
+}
+
+func NewJobManager(logOpts *logutil.LogOptions) *JobManager {
+	return &JobManager{
+		// 同一个任务上一次未执行完成时跳过本次执行
+		cron:    cron.New(cron.WithChain(cron.SkipIfStillRunning(cron.DiscardLogger))),
+		logOpts: logOpts,
+	}
+}
+
+// Register 注册定时任务，CronSpec 不合法时返回错误
+func (m *JobManager) Register(jobs ...Job) error {
+	for _, job := range jobs {
+		job := job
+		if _, err := m.cron.AddFunc(job.CronSpec(), func() { m.run(job) }); err != nil {
+			return fmt.Errorf("failed to register job %s: %v", job.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (m *JobManager) Start() {
+	m.cron.Start()
+}
+
+// Stop 停止调度，并返回等待正在执行的任务结束的 context
+func (m *JobManager) Stop() context.Context {
+	return m.cron.Stop()
+}
+
+func (m *JobManager) run(job Job) {
+	ctx := NewJobContext(job.Name(), m.logOpts)
+	ctx.Log(job.LogLevel(), job.Do(ctx))
+}
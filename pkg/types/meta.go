@@ -30,6 +30,7 @@ import (
 	"golang.org/x/crypto/ssh"
 	appv1 "k8s.io/api/apps/v1"
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/remotecommand"
 	"k8s.io/klog/v2"
 
@@ -200,6 +201,9 @@ func NewTurn(wsConn *websocket.Conn, sshClient *ssh.Client) (*Turn, error) {
 }
 
 func (t *Turn) Write(p []byte) (n int, err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
 	writer, err := t.WsConn.NextWriter(websocket.BinaryMessage)
 	if err != nil {
 		return 0, err
@@ -374,6 +378,36 @@ func (cs *ComponentSpec) Unmarshal(s string) error {
 	return nil
 }
 
+func (hf *HostFacts) Marshal() (string, error) {
+	data, err := json.Marshal(hf)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (hf *HostFacts) Unmarshal(s string) error {
+	if err := json.Unmarshal([]byte(s), hf); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (pn *PlanNodes) Marshal() (string, error) {
+	data, err := json.Marshal(pn)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (pn *PlanNodes) Unmarshal(s string) error {
+	if err := json.Unmarshal([]byte(s), pn); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (p PageRequest) IsPaged() bool {
 	return p.Page != 0 && p.Limit != 0
 }
@@ -415,6 +449,22 @@ func (l *ListOptions) IsDesc() bool {
 	return false
 }
 
+// BuildQuery 将名称和标签搜索条件转换为数据库查询条件，标签选择器不合法时返回错误
+func (l *ListOptions) BuildQuery() ([]db.Options, error) {
+	var opts []db.Options
+	if len(l.NameSelector) != 0 {
+		opts = append(opts, db.WithNameLike(l.NameSelector))
+	}
+	if len(l.LabelSelector) != 0 {
+		selector, err := labels.Parse(l.LabelSelector)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, db.WithLabelSelector(selector))
+	}
+	return opts, nil
+}
+
 func (l *ListOptions) BuildPageNation() []db.Options {
 	opts := []db.Options{db.WithPagination(l.Page, l.Limit)}
 	if l.IsDesc() {
@@ -36,6 +36,8 @@ type (
 		ObjectType model.ObjectType `json:"object_type" binding:"required,rbac_object"`
 		SID        string           `json:"sid" binding:"omitempty,rbac_sid"`
 		Operation  model.Operation  `json:"operation" binding:"required,rbac_operation"`
+		// kubernetes 资源的策略可以限定命名空间，为空时不限定
+		Namespace string `json:"namespace" binding:"omitempty,rbac_namespace"`
 	}
 
 	ListRBACPolicyRequest struct {
@@ -43,6 +45,7 @@ type (
 		ObjectType   *model.ObjectType `form:"object_type" binding:"omitempty,required_with=UserId,rbac_object"`
 		SID          *string           `form:"sid" binding:"omitempty,required_with=ObjectType,rbac_sid"`
 		Operation    *model.Operation  `form:"operation" binding:"omitempty,required_with=SID,rbac_operation"`
+		Namespace    *string           `form:"namespace" binding:"omitempty,required_with=Operation,rbac_namespace"`
 		*PageRequest `json:",inline"`
 	}
 
@@ -111,21 +114,152 @@ type (
 		Name      string `json:"name" binding:"omitempty"`       // optional
 		AliasName string `json:"alias_name" binding:"omitempty"` // optional
 		//Type        model.ClusterType `json:"cluster_type" binding:"omitempty,oneof=0 1"` // optional
-		KubeConfig  string `json:"kube_config" binding:"required"`  // required
 		Description string `json:"description" binding:"omitempty"` // optional
 		Protected   bool   `json:"protected" binding:"omitempty"`   // optional
+
+		// kubeConfig 和 credential 二选一，credential 会被转换为 kubeConfig 存储
+		KubeConfig string             `json:"kube_config" binding:"required_without=Credential,excluded_with=Credential"`
+		Credential *ClusterCredential `json:"credential" binding:"required_without=KubeConfig,excluded_with=KubeConfig"`
+
+		// optional 集群缓存的资源列表，为空时缓存默认资源
+		InformerResources []string `json:"informer_resources" binding:"omitempty"`
 	}
 
 	UpdateClusterRequest struct {
-		AliasName       *string `json:"alias_name" binding:"omitempty"`      // optional
-		Description     *string `json:"description" binding:"omitempty"`     // optional
-		ResourceVersion *int64  `json:"resource_version" binding:"required"` // required
+		AliasName       *string            `json:"alias_name" binding:"omitempty"`      // optional
+		Description     *string            `json:"description" binding:"omitempty"`     // optional
+		Labels          *map[string]string `json:"labels" binding:"omitempty"`          // optional, 全量替换集群标签
+		ResourceVersion *int64             `json:"resource_version" binding:"required"` // required
 	}
 
 	ProtectClusterRequest struct {
 		ResourceVersion *int64 `json:"resource_version" binding:"required"` // required
 		Protected       bool   `json:"protected" binding:"omitempty"`       // optional
 	}
+
+	// UpdateClusterKubeConfigRequest 更新集群 kubeConfig，用于凭证过期后的轮换
+	UpdateClusterKubeConfigRequest struct {
+		KubeConfig      string             `json:"kube_config" binding:"required_without=Credential,excluded_with=Credential"`
+		Credential      *ClusterCredential `json:"credential" binding:"required_without=KubeConfig,excluded_with=KubeConfig"`
+		ResourceVersion *int64             `json:"resource_version" binding:"required"` // required
+	}
+
+	// PreviewKubeConfigRequest 预览 kubeConfig 中的全部 context
+	PreviewKubeConfigRequest struct {
+		KubeConfig string `json:"kube_config" binding:"required"` // required
+	}
+
+	// ImportClustersRequest 从多 context 的 kubeConfig 中批量导入集群，每个 context 创建一个集群
+	ImportClustersRequest struct {
+		KubeConfig string                 `json:"kube_config" binding:"required"`                        // required
+		Contexts   []ImportClusterContext `json:"contexts" binding:"required,min=1,unique=Context,dive"` // required
+	}
+
+	ImportClusterContext struct {
+		Context     string `json:"context" binding:"required"`      // required
+		Name        string `json:"name" binding:"omitempty"`        // optional 为空时随机生成
+		AliasName   string `json:"alias_name" binding:"omitempty"`  // optional 为空时使用 context 名称
+		Description string `json:"description" binding:"omitempty"` // optional
+		Protected   bool   `json:"protected" binding:"omitempty"`   // optional
+	}
+
+	// ClusterCredential 不使用 kubeConfig 时的集群凭证，token 和客户端证书二选一
+	// 证书和私钥为 PEM 格式，可以是原始文本或者 base64 编码
+	ClusterCredential struct {
+		Server                string `json:"server" binding:"required,url"`                                         // required apiserver 地址
+		CertificateAuthority  string `json:"certificate_authority" binding:"omitempty"`                             // optional 为空时使用系统 CA
+		InsecureSkipTLSVerify bool   `json:"insecure_skip_tls_verify" binding:"excluded_with=CertificateAuthority"` // optional
+		Token                 string `json:"token" binding:"required_without=ClientCertificate,excluded_with=ClientCertificate"`
+		ClientCertificate     string `json:"client_certificate" binding:"required_without=Token,required_with=ClientKey"`
+		ClientKey             string `json:"client_key" binding:"required_with=ClientCertificate"`
+	}
+
+	// UpdateClusterInformersRequest 调整集群缓存的资源，运行时生效
+	UpdateClusterInformersRequest struct {
+		Resources       []string `json:"resources" binding:"required"`        // required
+		ResourceVersion *int64   `json:"resource_version" binding:"required"` // required
+	}
+
+	// HostAuth 主机的 ssh 登陆凭证，根据 type 填写 password 或者 key
+	HostAuth struct {
+		Type     AuthType      `json:"type" binding:"required,oneof=password key"`   // required
+		Password *PasswordSpec `json:"password" binding:"required_if=Type password"` // optional
+		Key      *KeySpec      `json:"key" binding:"required_if=Type key"`           // optional
+	}
+
+	// CreateHostRequest 录入主机，登陆凭证加密后保存
+	CreateHostRequest struct {
+		Name    string            `json:"name" binding:"required,max=128"`                // required
+		Address string            `json:"address" binding:"required,ip|hostname_rfc1123"` // required
+		Port    int               `json:"port" binding:"omitempty,min=1,max=65535"`       // optional 默认 22
+		Role    NodeRole          `json:"role" binding:"omitempty,oneof=master node"`     // optional 默认 node
+		Labels  map[string]string `json:"labels" binding:"omitempty"`                     // optional
+		Auth    HostAuth          `json:"auth" binding:"required"`                        // required
+	}
+
+	// UpdateHostRequest 更新主机，只更新非空的字段
+	UpdateHostRequest struct {
+		Name            *string            `json:"name" binding:"omitempty,max=128"`                // optional
+		Address         *string            `json:"address" binding:"omitempty,ip|hostname_rfc1123"` // optional
+		Port            *int               `json:"port" binding:"omitempty,min=1,max=65535"`        // optional
+		Role            *NodeRole          `json:"role" binding:"omitempty,oneof=master node"`      // optional
+		Labels          *map[string]string `json:"labels" binding:"omitempty"`                      // optional
+		Auth            *HostAuth          `json:"auth" binding:"omitempty"`                        // optional
+		ResetHostKey    bool               `json:"reset_host_key" binding:"omitempty"`              // optional 主机重装后重置记录的 host key
+		ResourceVersion *int64             `json:"resource_version" binding:"required"`             // required
+	}
+
+	// ListHostOptions 主机列表支持按角色和标签过滤
+	ListHostOptions struct {
+		ListOptions `json:",inline"`
+		Role        NodeRole `form:"role" binding:"omitempty,oneof=master node"`
+	}
+
+	// CreatePlanRequest 创建自建集群的部署规划，未填写的配置使用默认值
+	CreatePlanRequest struct {
+		Name        string         `json:"name" binding:"required,max=128"` // required
+		Description string         `json:"description" binding:"omitempty"` // optional
+		Kubernetes  KubernetesSpec `json:"kubernetes"`
+		Network     NetworkSpec    `json:"network"`
+		Runtime     RuntimeSpec    `json:"runtime"`
+		Component   ComponentSpec  `json:"component"`
+		Nodes       PlanNodes      `json:"nodes" binding:"omitempty,dive"` // optional 可以在部署前补充
+	}
+
+	// UpdatePlanRequest 更新部署规划，只更新非空的字段，更新后的规划整体重新校验
+	UpdatePlanRequest struct {
+		Name            *string         `json:"name" binding:"omitempty,max=128"`    // optional
+		Description     *string         `json:"description" binding:"omitempty"`     // optional
+		Kubernetes      *KubernetesSpec `json:"kubernetes" binding:"omitempty"`      // optional
+		Network         *NetworkSpec    `json:"network" binding:"omitempty"`         // optional
+		Runtime         *RuntimeSpec    `json:"runtime" binding:"omitempty"`         // optional
+		Component       *ComponentSpec  `json:"component" binding:"omitempty"`       // optional
+		Nodes           *PlanNodes      `json:"nodes" binding:"omitempty,dive"`      // optional
+		ResourceVersion *int64          `json:"resource_version" binding:"required"` // required
+	}
+
+	// LinkPlanClusterRequest 关联规划部署出的集群，cluster_id 为 0 时解除关联
+	LinkPlanClusterRequest struct {
+		ClusterId *int64 `json:"cluster_id" binding:"required,gte=0"` // required
+	}
+
+	// ScaleWorkloadRequest 调整 deployment 和 statefulset 的副本数
+	ScaleWorkloadRequest struct {
+		Replicas *int32 `json:"replicas" binding:"required,gte=0"` // required
+	}
+
+	// ApplyRequest 通过 server-side apply 应用多文档的 yaml
+	ApplyRequest struct {
+		Manifest  string `json:"manifest" binding:"required"`   // required
+		Namespace string `json:"namespace" binding:"omitempty"` // optional 未指定命名空间的资源使用该命名空间，默认为 default
+		DryRun    bool   `json:"dry_run" binding:"omitempty"`   // optional 仅返回与线上对象的差异，不实际修改
+		Force     bool   `json:"force" binding:"omitempty"`     // optional 字段冲突时强制获取字段的所有权
+	}
+
+	// DeletePodRequest 删除 pod，GracePeriodSeconds 为空时使用 pod 自身的配置
+	DeletePodRequest struct {
+		GracePeriodSeconds *int64 `form:"gracePeriodSeconds" binding:"omitempty,gte=0"` // optional
+	}
 )
 
 type (
@@ -137,6 +271,28 @@ type (
 		*model.User `json:"-"`
 	}
 
+	// ObjectVersionResponse 操作后的对象版本，用于展示操作的执行进度
+	// 当 ObservedGeneration 追上 Generation 时说明控制器已经处理了本次变更
+	ObjectVersionResponse struct {
+		Name               string `json:"name"`
+		Namespace          string `json:"namespace"`
+		ResourceVersion    string `json:"resource_version"`
+		Generation         int64  `json:"generation"`
+		ObservedGeneration int64  `json:"observed_generation"`
+	}
+
+	// ApplyResult 单个对象的应用结果，Action 为 created/configured/unchanged/failed
+	ApplyResult struct {
+		APIVersion      string `json:"api_version"`
+		Kind            string `json:"kind"`
+		Namespace       string `json:"namespace,omitempty"`
+		Name            string `json:"name"`
+		Action          string `json:"action"`
+		ResourceVersion string `json:"resource_version,omitempty"`
+		Diff            string `json:"diff,omitempty"` // dry-run 时与线上对象的差异，- 为线上 + 为应用后
+		Error           string `json:"error,omitempty"`
+	}
+
 	// PageResponse 分页查询返回值
 	PageResponse struct {
 		PageRequest `json:",inline"` // 分页请求属性
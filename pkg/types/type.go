@@ -36,6 +36,12 @@ type VulpesObjectMeta struct {
 	Name      string `uri:"name"`
 }
 
+// VulpesClusterMeta 集群级别 kubernetes 资源的 URI 参数，如 node
+type VulpesClusterMeta struct {
+	Cluster string `uri:"cluster" binding:"required"`
+	Name    string `uri:"name"`
+}
+
 type VulpesMeta struct {
 	// vuples 对象 ID
 	Id int64 `json:"id"`
@@ -60,7 +66,7 @@ type Cluster struct {
 
 	Name      string              `json:"name"`
 	AliasName string              `json:"alias_name"`
-	Status    model.ClusterStatus `json:"status"` //集群运行状态 0: 运行中 2: 集群失联 4: 集群 node 节点不健康
+	Status    model.ClusterStatus `json:"status"` //集群运行状态 0: 运行中 2: 集群失联 4: 集群 node 节点不健康 6: 缓存构建中 8: 缓存构建失败
 
 	// 0: 标准集群 1: 自建集群
 	//ClusterType model.ClusterType `json:"cluster_type"`
@@ -80,10 +86,101 @@ type Cluster struct {
 	// 集群用途描述，可以为空
 	Description string `json:"description"`
 
+	// 集群标签，e.g. env=prod, region=cn-east
+	Labels map[string]string `json:"labels"`
+
+	// 集群缓存的资源列表，格式为 group/version/resource，核心组为 version/resource
+	InformerResources []string `json:"informer_resources"`
+
+	// 集群的删除时间和彻底清理时间，仅已删除的集群返回，清理前可以恢复
+	GmtDeleted *time.Time `json:"gmt_deleted,omitempty"`
+	GmtPurge   *time.Time `json:"gmt_purge,omitempty"`
+
 	KubernetesMeta `json:",inline"`
 	TimeMeta       `json:",inline"`
 }
 
+// KubeConfigContext kubeConfig 中的 context，用于导入前预览
+type KubeConfigContext struct {
+	Name      string `json:"name"`
+	Cluster   string `json:"cluster"`
+	Server    string `json:"server"`
+	User      string `json:"user"`
+	Namespace string `json:"namespace,omitempty"`
+	Current   bool   `json:"current"`
+	Error     string `json:"error,omitempty"` // 不允许导入的原因，例如使用了 exec 插件
+}
+
+// ImportClusterResult 批量导入时单个 context 的导入结果
+type ImportClusterResult struct {
+	Context string `json:"context"`
+	Name    string `json:"name,omitempty"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ClusterInformer 集群缓存资源的运行和同步状态
+type ClusterInformer struct {
+	Resource string `json:"resource"`
+	Required bool   `json:"required"` // 集群状态探测依赖的资源，不允许移除
+	Started  bool   `json:"started"`  // informer 在第一次访问时启动，长时间未访问时停止
+	Synced   bool   `json:"synced"`
+}
+
+// ClusterStatusRecord 集群状态变更记录
+type ClusterStatusRecord struct {
+	Id        int64               `json:"id"`
+	ClusterId int64               `json:"cluster_id"`
+	From      model.ClusterStatus `json:"from"`
+	To        model.ClusterStatus `json:"to"`
+	Message   string              `json:"message"`
+	GmtCreate time.Time           `json:"gmt_create"`
+}
+
+// Host 主机列表中的机器，不返回登陆凭证
+type Host struct {
+	VulpesMeta `json:",inline"`
+
+	Name     string            `json:"name"`
+	Address  string            `json:"address"`
+	Port     int               `json:"port"`
+	AuthType AuthType          `json:"auth_type"`
+	User     string            `json:"user"`
+	Role     NodeRole          `json:"role"`
+	Labels   map[string]string `json:"labels"`
+
+	// 最近一次检查的结果
+	Status        string     `json:"status"`
+	Message       string     `json:"message,omitempty"`
+	Facts         HostFacts  `json:"facts"`
+	LastCheckTime *time.Time `json:"last_check_time,omitempty"`
+	// 第一次检查成功时记录的 host key 指纹
+	HostKeyFingerprint string `json:"host_key_fingerprint,omitempty"`
+
+	TimeMeta `json:",inline"`
+}
+
+// HostFacts 检查主机时通过 ssh 采集的系统信息
+type HostFacts struct {
+	OS               string `json:"os,omitempty"`
+	Kernel           string `json:"kernel,omitempty"`
+	Arch             string `json:"arch,omitempty"`
+	Cpu              int    `json:"cpu,omitempty"`    // 核数
+	Memory           int64  `json:"memory,omitempty"` // 总内存，单位 byte
+	ContainerRuntime string `json:"container_runtime,omitempty"`
+}
+
+// HostSession 节点 SSH 终端的会话录制
+type HostSession struct {
+	Id        int64     `json:"id"`
+	HostId    int64     `json:"host_id"`
+	RequestId string    `json:"request_id"` // 对应审计记录的 request_id
+	Operator  string    `json:"operator"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Input     string    `json:"input,omitempty"`
+}
+
 // KubernetesMeta 记录 kubernetes 集群的数据
 type KubernetesMeta struct {
 	// 集群的版本
@@ -101,6 +198,23 @@ type Resources struct {
 	Memory string `json:"memory"`
 }
 
+// ResourceUsage 资源的使用量、申请量、限制量和可分配总量，cpu 的单位为核
+// 集群未安装 metrics-server 时 MetricsAvailable 为 false，使用量为空
+type ResourceUsage struct {
+	Cpu              ResourceUsageDetail `json:"cpu"`
+	Memory           ResourceUsageDetail `json:"memory"`
+	MetricsAvailable bool                `json:"metrics_available"`
+}
+
+type ResourceUsageDetail struct {
+	Used             string  `json:"used,omitempty"`
+	Requested        string  `json:"requested"`
+	Limits           string  `json:"limits"`
+	Capacity         string  `json:"capacity"`
+	UsedPercent      float64 `json:"used_percent"`
+	RequestedPercent float64 `json:"requested_percent"`
+}
+
 type User struct {
 	VulpesMeta `json:",inline"`
 
@@ -124,17 +238,25 @@ type Audit struct {
 	VulpesMeta `json:",inline"`
 	TimeMeta   `json:",inline"`
 
+	RequestId  string                     `json:"request_id"`
 	Ip         string                     `json:"ip"`
-	Action     string                     `json:"action"`        // 操作动作
-	Status     model.AuditOperationStatus `json:"status"`        // 操作状态
-	Operator   string                     `json:"operator"`      // 操作人
-	Path       string                     `json:"path"`          // 操作路径
-	ObjectType model.ObjectType           `json:"resource_type"` // 资源类型
+	Action     string                     `json:"action"`          // 操作动作
+	Status     model.AuditOperationStatus `json:"status"`          // 操作状态
+	Operator   string                     `json:"operator"`        // 操作人
+	Path       string                     `json:"path"`            // 操作路径
+	ObjectType model.ObjectType           `json:"resource_type"`   // 资源类型
+	Event      string                     `json:"event,omitempty"` // 操作详情
 }
 
 type AuthType string
 
+const (
+	PasswordAuth AuthType = "password"
+	KeyAuth      AuthType = "key"
+)
+
 type KeySpec struct {
+	User string `json:"user,omitempty"`
 	Data string `json:"data,omitempty"`
 	File string `json:"-"`
 }
@@ -157,11 +279,22 @@ type KubeObject struct {
 	Pods        []v1.Pod
 }
 
+// ResourceMeta 通过 group/version/resource 访问集群缓存的任意资源，例如 CRD
+// 核心组资源的 group 为 core，集群级别的资源或者全部命名空间的资源 namespace 为空
+type ResourceMeta struct {
+	Cluster   string `uri:"cluster" binding:"required"`
+	Namespace string `uri:"namespace"`
+	Group     string `uri:"group" binding:"required"`
+	Version   string `uri:"version" binding:"required"`
+	Resource  string `uri:"resource" binding:"required"`
+	Name      string `uri:"name"`
+}
+
 // WebShellOptions ws API 参数定义
 type WebShellOptions struct {
-	Cluster   string `form:"cluster"`
-	Namespace string `form:"namespace"`
-	Pod       string `form:"pod"`
+	Cluster   string `form:"cluster" uri:"cluster"`
+	Namespace string `form:"namespace" binding:"required"`
+	Pod       string `form:"pod" binding:"required"`
 	Container string `form:"container"`
 	Command   string `form:"command"`
 }
@@ -186,6 +319,9 @@ type TerminalSession struct {
 }
 
 type Turn struct {
+	// ssh session 的 stdout 和 stderr 会并发写入，websocket 不支持并发写
+	lock sync.Mutex
+
 	StdinPipe io.WriteCloser
 	Session   *ssh.Session
 	WsConn    *websocket.Conn
@@ -206,14 +342,65 @@ type EventOptions struct {
 	Name       string `form:"name"`
 	Kind       string `form:"kind"`
 	Namespaced bool   `form:"namespaced"`
-	Limit      int64  `form:"limit"`
+	Limit      int64  `form:"limit" binding:"omitempty,gt=0"`
+}
+
+// EventList 按照最后发生时间倒序排列的事件，以及按照 reason 聚合的统计
+type EventList struct {
+	Items   []v1.Event     `json:"items"`
+	Reasons []EventSummary `json:"reasons"`
+}
+
+type EventSummary struct {
+	Reason        string    `json:"reason"`
+	Type          string    `json:"type"`
+	Count         int32     `json:"count"`
+	LastTimestamp time.Time `json:"last_timestamp"`
 }
 
 type PodLogOptions struct {
-	Container string `form:"container"`
-	TailLines int64  `form:"tailLines"`
+	Container  string `form:"container"`
+	TailLines  int64  `form:"tailLines" binding:"omitempty,gt=0"`
+	LimitBytes int64  `form:"limitBytes" binding:"omitempty,gt=0"` // 返回日志的最大字节数
+	Follow     bool   `form:"follow"`                              // 持续输出日志，通过 websocket 或者 SSE 返回
+	Previous   bool   `form:"previous"`                            // 获取上一个已终止容器的日志
+	SinceTime  string `form:"sinceTime"`                           // RFC3339 格式，仅返回该时间之后的日志
+	Timestamps bool   `form:"timestamps"`                          // 每行日志前增加时间戳
 }
 
+// Plan 自建集群的部署规划
+type Plan struct {
+	VulpesMeta `json:",inline"`
+
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ClusterId   int64  `json:"cluster_id"` // 根据规划部署出的集群，未关联时为 0
+
+	Kubernetes KubernetesSpec `json:"kubernetes"`
+	Network    NetworkSpec    `json:"network"`
+	Runtime    RuntimeSpec    `json:"runtime"`
+	Component  ComponentSpec  `json:"component"`
+	Nodes      PlanNodes      `json:"nodes"`
+
+	TimeMeta `json:",inline"`
+}
+
+// NodeRole 节点在集群中的角色
+type NodeRole string
+
+const (
+	RoleMaster NodeRole = "master"
+	RoleNode   NodeRole = "node"
+)
+
+// PlanNode 规划中部署的节点，节点来自主机列表
+type PlanNode struct {
+	HostId int64    `json:"host_id" binding:"required"`
+	Role   NodeRole `json:"role" binding:"required,oneof=master node"`
+}
+
+type PlanNodes []PlanNode
+
 type KubernetesSpec struct {
 	EnablePublicIp    bool   `json:"enable_public_ip"`
 	ApiServer         string `json:"api_server"`
@@ -271,4 +458,15 @@ type RBACPolicy struct {
 	ObjectType model.ObjectType `json:"resource_type,omitempty"`
 	StringID   string           `json:"sid,omitempty"`
 	Operation  model.Operation  `json:"operation,omitempty"`
+	Namespace  string           `json:"namespace,omitempty"`
+}
+
+// UserKubeConfig 按照用户在 vulpes 中的权限签发的 kubeConfig
+type UserKubeConfig struct {
+	KubeConfig          string    `json:"kube_config"`
+	ExpirationTimestamp time.Time `json:"expiration_timestamp"`
+	// 是否拥有不限定命名空间的权限
+	ClusterScoped bool `json:"cluster_scoped"`
+	// 拥有权限的命名空间
+	Namespaces []string `json:"namespaces"`
 }
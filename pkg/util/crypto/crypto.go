@@ -0,0 +1,223 @@
+/*
+Copyright 2025 The Vuples Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	// 加密数据格式: enc:v1:<master key id>:<加密后的数据密钥>:<加密后的数据>
+	encryptedPrefix = "enc:v1:"
+
+	keySize = 32 // AES-256
+)
+
+var (
+	ErrEmptyKey      = errors.New("encryption key is required")
+	ErrInvalidKey    = fmt.Errorf("encryption key must be %d bytes encoded in base64", keySize)
+	ErrInvalidData   = errors.New("invalid encrypted data")
+	ErrKeyNotFound   = errors.New("encryption key of the data is not found")
+	ErrDuplicatedKey = errors.New("duplicated encryption key")
+)
+
+// Key 主密钥，仅用于加密每条数据各自的数据密钥
+type Key struct {
+	id   string
+	aead cipher.AEAD
+}
+
+func newKey(raw []byte) (*Key, error) {
+	if len(raw) != keySize {
+		return nil, ErrInvalidKey
+	}
+	aead, err := newAEAD(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(raw)
+	return &Key{
+		id:   hex.EncodeToString(sum[:])[:8],
+		aead: aead,
+	}, nil
+}
+
+func (k *Key) ID() string {
+	return k.id
+}
+
+// Keyring 使用信封加密保护敏感数据
+// primary 用于加密，所有的密钥都可以用于解密，以支持密钥轮转
+type Keyring struct {
+	primary *Key
+	keys    map[string]*Key
+}
+
+// NewKeyring 创建密钥环，primary 为当前使用的主密钥，previous 为仅用于解密的历史主密钥
+func NewKeyring(primary []byte, previous ...[]byte) (*Keyring, error) {
+	p, err := newKey(primary)
+	if err != nil {
+		return nil, err
+	}
+
+	kr := &Keyring{
+		primary: p,
+		keys:    map[string]*Key{p.id: p},
+	}
+	for _, raw := range previous {
+		k, err := newKey(raw)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := kr.keys[k.id]; ok {
+			return nil, ErrDuplicatedKey
+		}
+		kr.keys[k.id] = k
+	}
+	return kr, nil
+}
+
+func (kr *Keyring) PrimaryKeyID() string {
+	return kr.primary.id
+}
+
+// Encrypt 生成随机的数据密钥加密数据，再使用主密钥加密数据密钥
+func (kr *Keyring) Encrypt(plaintext []byte) (string, error) {
+	dek := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, dek); err != nil {
+		return "", err
+	}
+	dataAEAD, err := newAEAD(dek)
+	if err != nil {
+		return "", err
+	}
+
+	wrappedKey, err := seal(kr.primary.aead, dek)
+	if err != nil {
+		return "", err
+	}
+	data, err := seal(dataAEAD, plaintext)
+	if err != nil {
+		return "", err
+	}
+
+	return encryptedPrefix + strings.Join([]string{
+		kr.primary.id,
+		base64.StdEncoding.EncodeToString(wrappedKey),
+		base64.StdEncoding.EncodeToString(data),
+	}, ":"), nil
+}
+
+// Decrypt 解密 Encrypt 生成的数据
+func (kr *Keyring) Decrypt(s string) ([]byte, error) {
+	if !IsEncrypted(s) {
+		return nil, ErrInvalidData
+	}
+	parts := strings.Split(strings.TrimPrefix(s, encryptedPrefix), ":")
+	if len(parts) != 3 {
+		return nil, ErrInvalidData
+	}
+
+	key, ok := kr.keys[parts[0]]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	wrappedKey, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidData
+	}
+	data, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidData
+	}
+
+	dek, err := open(key.aead, wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	dataAEAD, err := newAEAD(dek)
+	if err != nil {
+		return nil, err
+	}
+	return open(dataAEAD, data)
+}
+
+// IsEncrypted 判断数据是否已经加密，用于兼容历史的明文数据
+func IsEncrypted(s string) bool {
+	return strings.HasPrefix(s, encryptedPrefix)
+}
+
+// LoadKey 读取 base64 编码的主密钥，key 优先于 keyFile
+func LoadKey(key string, keyFile string) ([]byte, error) {
+	if len(key) == 0 && len(keyFile) != 0 {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read encryption key file: %v", err)
+		}
+		key = string(data)
+	}
+
+	key = strings.TrimSpace(key)
+	if len(key) == 0 {
+		return nil, ErrEmptyKey
+	}
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(raw) != keySize {
+		return nil, ErrInvalidKey
+	}
+	return raw, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal 加密数据，随机 nonce 拼接在密文之前
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(aead cipher.AEAD, data []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, ErrInvalidData
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrInvalidData
+	}
+	return plaintext, nil
+}
@@ -33,6 +33,7 @@ var (
 	ErrUserNotFound          = errors.New("用户不存在")
 	ErrNotAcceptable         = errors.New("有任务正在执行，请稍后再试")
 	ErrClusterNotFound       = errors.New("集群不存在")
+	ErrClusterExists         = errors.New("集群已存在")
 	ErrUserPassword          = errors.New("密码错误")
 	ErrInternal              = errors.New("服务器内部错误")
 	PolicyExistError         = errors.New("策略已存在")
@@ -40,6 +41,11 @@ var (
 	ErrTenantNotFound        = errors.New("租户不存在")
 	ErrDuplicatedPassword    = errors.New("新密码与旧密码相同")
 	ErrAuditNotFound         = errors.New("审计记录不存在")
+	ErrHostNotFound          = errors.New("主机不存在")
+	ErrHostExists            = errors.New("主机已存在")
+	ErrHostSessionNotFound   = errors.New("会话记录不存在")
+	ErrPlanNotFound          = errors.New("部署规划不存在")
+	ErrPlanExists            = errors.New("部署规划已存在")
 	ErrProjectDuplicatedName = errors.New("企业+项目名称不能同时重复")
 
 	ErrContainerNotFound = errors.New("容器不存在")
@@ -18,6 +18,7 @@ package util
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -72,6 +73,19 @@ func MultiSizeConvert(size1, size2 int64) (string, string) {
 	return formatSize(size1, sizeUnitArray), formatSize(size2, sizeUnitArray)
 }
 
+// MultiCpuConvert 将两个 millicore 的值转换为以核为单位的可读格式
+func MultiCpuConvert(milli1, milli2 int64) (string, string) {
+	return fmt.Sprintf("%.2f", float64(milli1)/1000), fmt.Sprintf("%.2f", float64(milli2)/1000)
+}
+
+// Percent 计算 part 占 total 的百分比，保留两位小数
+func Percent(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*10000) / 100
+}
+
 // GenerateRequestID return a request ID string with random suffix.
 func GenerateRequestID() string {
 	return fmt.Sprintf("%s-%06d", time.Now().Format("20060102150405"), rand.Intn(1000000))